@@ -0,0 +1,102 @@
+package giter
+
+// Enumerate returns a Seq2 of the elements of seq paired with their index, starting from 0.
+//
+// EXAMPLE:
+//
+//	giter.Enumerate(giter.Of("a", "b")) 👉 (0, "a"), (1, "b")
+func Enumerate[T any](seq Seq[T]) Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		i := 0
+		seq(func(v T) bool {
+			ok := yield(i, v)
+			i++
+			return ok
+		})
+	}
+}
+
+// Intersperse returns a seq of the elements of seq with sep between each two adjacent elements.
+//
+// EXAMPLE:
+//
+//	giter.ToSlice(giter.Intersperse(giter.Of(1, 2, 3), 0)) 👉 [1, 0, 2, 0, 3]
+func Intersperse[T any](seq Seq[T], sep T) Seq[T] {
+	return func(yield func(T) bool) {
+		first := true
+		seq(func(v T) bool {
+			if !first && !yield(sep) {
+				return false
+			}
+			first = false
+			return yield(v)
+		})
+	}
+}
+
+// Interleave returns a seq taking one element from each seq in turn,
+// an exhausted seq is skipped and the seq ends when all seqs are exhausted.
+//
+// EXAMPLE:
+//
+//	giter.ToSlice(giter.Interleave(giter.Of(1, 2, 3), giter.Of(10), giter.Of(100, 200))) 👉 [1, 10, 100, 2, 200, 3]
+func Interleave[T any](seqs ...Seq[T]) Seq[T] {
+	return func(yield func(T) bool) {
+		nexts := make([]func() (T, bool), 0, len(seqs))
+		for _, seq := range seqs {
+			next, stop := pull(seq)
+			defer stop()
+			nexts = append(nexts, next)
+		}
+		for len(nexts) > 0 {
+			alive := nexts[:0]
+			for _, next := range nexts {
+				v, ok := next()
+				if !ok {
+					continue
+				}
+				if !yield(v) {
+					return
+				}
+				alive = append(alive, next)
+			}
+			nexts = alive
+		}
+	}
+}
+
+// Flatten returns a seq of the elements of all seqs yielded by seq, in order.
+//
+// EXAMPLE:
+//
+//	giter.ToSlice(giter.Flatten(giter.Of(giter.Of(1, 2), giter.Of(3)))) 👉 [1, 2, 3]
+func Flatten[T any](seq Seq[Seq[T]]) Seq[T] {
+	return func(yield func(T) bool) {
+		seq(func(inner Seq[T]) bool {
+			ok := true
+			inner(func(v T) bool {
+				ok = yield(v)
+				return ok
+			})
+			return ok
+		})
+	}
+}
+
+// FlattenSlice returns a seq of the elements of all slices yielded by seq, in order.
+//
+// EXAMPLE:
+//
+//	giter.ToSlice(giter.FlattenSlice(giter.Of([]int{1, 2}, []int{3}))) 👉 [1, 2, 3]
+func FlattenSlice[T any](seq Seq[[]T]) Seq[T] {
+	return func(yield func(T) bool) {
+		seq(func(s []T) bool {
+			for _, v := range s {
+				if !yield(v) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+}
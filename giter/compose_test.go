@@ -0,0 +1,47 @@
+package giter_test
+
+import (
+	"testing"
+
+	"github.com/dashjay/gog/giter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompose(t *testing.T) {
+	t.Run("enumerate", func(t *testing.T) {
+		var idxs []int
+		var values []string
+		giter.Enumerate(giter.Of("a", "b", "c"))(func(i int, v string) bool {
+			idxs = append(idxs, i)
+			values = append(values, v)
+			return i < 1
+		})
+		assert.Equal(t, []int{0, 1}, idxs)
+		assert.Equal(t, []string{"a", "b"}, values)
+	})
+
+	t.Run("intersperse", func(t *testing.T) {
+		assert.Equal(t, []int{1, 0, 2, 0, 3}, giter.ToSlice(giter.Intersperse(giter.Of(1, 2, 3), 0)))
+		assert.Equal(t, []int{1}, giter.ToSlice(giter.Intersperse(giter.Of(1), 0)))
+		assert.Empty(t, giter.ToSlice(giter.Intersperse(giter.Empty[int](), 0)))
+		assert.Equal(t, []int{1, 0}, giter.ToSlice(giter.Limit(giter.Intersperse(giter.Of(1, 2, 3), 0), 2)))
+		assert.Equal(t, "a, b",
+			giter.Join(giter.Intersperse(giter.Of("a", "b"), ", "), ""))
+	})
+
+	t.Run("interleave", func(t *testing.T) {
+		assert.Equal(t, []int{1, 10, 100, 2, 200, 3},
+			giter.ToSlice(giter.Interleave(giter.Of(1, 2, 3), giter.Of(10), giter.Of(100, 200))))
+		assert.Equal(t, []int{1, 10, 2},
+			giter.ToSlice(giter.Limit(giter.Interleave(giter.Of(1, 2, 3), giter.Of(10, 20)), 3)))
+		assert.Empty(t, giter.ToSlice(giter.Interleave[int]()))
+	})
+
+	t.Run("flatten", func(t *testing.T) {
+		assert.Equal(t, []int{1, 2, 3}, giter.ToSlice(giter.Flatten(giter.Of(giter.Of(1, 2), giter.Empty[int](), giter.Of(3)))))
+		assert.Equal(t, []int{1, 2}, giter.ToSlice(giter.Limit(giter.Flatten(giter.Of(giter.Of(1), giter.Of(2, 3))), 2)))
+
+		assert.Equal(t, []int{1, 2, 3}, giter.ToSlice(giter.FlattenSlice(giter.Of([]int{1, 2}, nil, []int{3}))))
+		assert.Equal(t, []int{1, 2}, giter.ToSlice(giter.Limit(giter.FlattenSlice(giter.Of([]int{1}, []int{2, 3})), 2)))
+	})
+}
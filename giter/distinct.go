@@ -0,0 +1,107 @@
+package giter
+
+import "github.com/dashjay/gog/gstl"
+
+// Distinct returns a seq of the elements of seq with duplicates removed, keeping the first occurrence.
+// All distinct elements are remembered, use DistinctLRU to cap the memory for unbounded seqs.
+//
+// EXAMPLE:
+//
+//	giter.ToSlice(giter.Distinct(giter.Of(1, 2, 1, 3, 2))) 👉 [1, 2, 3]
+func Distinct[T comparable](seq Seq[T]) Seq[T] {
+	return DistinctBy(seq, func(v T) T { return v })
+}
+
+// DistinctBy is like Distinct but two elements are duplicates if they have the same key evaluated by key.
+func DistinctBy[T any, K comparable](seq Seq[T], key func(T) K) Seq[T] {
+	return func(yield func(T) bool) {
+		seen := make(map[K]struct{})
+		seq(func(v T) bool {
+			k := key(v)
+			if _, ok := seen[k]; ok {
+				return true
+			}
+			seen[k] = struct{}{}
+			return yield(v)
+		})
+	}
+}
+
+// DistinctLRU is like Distinct but only remembers the capacity most recently seen elements,
+// so a duplicate is yielded again if it has not been seen among the last capacity distinct elements.
+func DistinctLRU[T comparable](seq Seq[T], capacity int) Seq[T] {
+	return DistinctByLRU(seq, func(v T) T { return v }, capacity)
+}
+
+// DistinctByLRU is like DistinctBy but only remembers the capacity most recently seen keys, see DistinctLRU.
+func DistinctByLRU[T any, K comparable](seq Seq[T], key func(T) K, capacity int) Seq[T] {
+	if capacity <= 0 {
+		panic("giter: DistinctByLRU capacity must be positive")
+	}
+	return func(yield func(T) bool) {
+		recent := gstl.New[K]()
+		seen := make(map[K]*gstl.Element[K], capacity)
+		seq(func(v T) bool {
+			k := key(v)
+			if e, ok := seen[k]; ok {
+				recent.MoveToFront(e)
+				return true
+			}
+			if recent.Len() == capacity {
+				oldest := recent.Back()
+				delete(seen, oldest.Value)
+				recent.Remove(oldest)
+			}
+			seen[k] = recent.PushFront(k)
+			return yield(v)
+		})
+	}
+}
+
+// DedupConsecutive returns a seq collapsing runs of equal consecutive elements of seq into one.
+//
+// EXAMPLE:
+//
+//	giter.ToSlice(giter.DedupConsecutive(giter.Of(1, 1, 2, 2, 1))) 👉 [1, 2, 1]
+func DedupConsecutive[T comparable](seq Seq[T]) Seq[T] {
+	return func(yield func(T) bool) {
+		var prev T
+		first := true
+		seq(func(v T) bool {
+			if !first && v == prev {
+				return true
+			}
+			first, prev = false, v
+			return yield(v)
+		})
+	}
+}
+
+// ChunkBy returns a seq of chunks of consecutive elements of seq having the same key evaluated by key.
+// Each chunk is a new slice which is not reused by the seq.
+//
+// EXAMPLE:
+//
+//	giter.ToSlice(giter.ChunkBy(giter.Of(1, 3, 2, 4, 5), func(x int) bool { return x%2 == 0 })) 👉 [[1, 3], [2, 4], [5]]
+func ChunkBy[T any, K comparable](seq Seq[T], key func(T) K) Seq[[]T] {
+	return func(yield func([]T) bool) {
+		var chunk []T
+		var chunkKey K
+		stopped := false
+		seq(func(v T) bool {
+			k := key(v)
+			if len(chunk) > 0 && k != chunkKey {
+				if !yield(chunk) {
+					stopped = true
+					return false
+				}
+				chunk = nil
+			}
+			chunk, chunkKey = append(chunk, v), k
+			return true
+		})
+		if !stopped && len(chunk) > 0 {
+			yield(chunk)
+		}
+	}
+}
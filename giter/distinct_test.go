@@ -0,0 +1,46 @@
+package giter_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dashjay/gog/giter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistinct(t *testing.T) {
+	t.Run("distinct", func(t *testing.T) {
+		assert.Equal(t, []int{1, 2, 3}, giter.ToSlice(giter.Distinct(giter.Of(1, 2, 1, 3, 2))))
+		assert.Equal(t, []int{1, 2}, giter.ToSlice(giter.Limit(giter.Distinct(giter.Of(1, 1, 2, 3)), 2)))
+		assert.Equal(t, []string{"a", "B"},
+			giter.ToSlice(giter.DistinctBy(giter.Of("a", "A", "B", "b"), strings.ToLower)))
+
+		// seqs can be iterated again
+		seq := giter.Distinct(giter.Of(1, 1, 2))
+		assert.Equal(t, giter.ToSlice(seq), giter.ToSlice(seq))
+	})
+
+	t.Run("distinct lru", func(t *testing.T) {
+		// 1 is refreshed by its duplicate, so 2 is evicted first
+		assert.Equal(t, []int{1, 2, 3, 2},
+			giter.ToSlice(giter.DistinctLRU(giter.Of(1, 2, 1, 3, 1, 2), 2)))
+		assert.Equal(t, []int{1, 2, 3},
+			giter.ToSlice(giter.DistinctLRU(giter.Of(1, 2, 1, 3, 1, 2), 3)))
+		assert.Equal(t, []string{"a", "B", "a"},
+			giter.ToSlice(giter.DistinctByLRU(giter.Of("a", "A", "B", "b", "a"), strings.ToLower, 1)))
+		assert.Panics(t, func() { giter.DistinctLRU(giter.Of(1), 0) })
+	})
+
+	t.Run("dedup consecutive", func(t *testing.T) {
+		assert.Equal(t, []int{1, 2, 1}, giter.ToSlice(giter.DedupConsecutive(giter.Of(1, 1, 2, 2, 1))))
+		assert.Equal(t, []int{0, 1}, giter.ToSlice(giter.DedupConsecutive(giter.Of(0, 0, 1))))
+		assert.Empty(t, giter.ToSlice(giter.DedupConsecutive(giter.Empty[int]())))
+	})
+
+	t.Run("chunk by", func(t *testing.T) {
+		isEven := func(x int) bool { return x%2 == 0 }
+		assert.Equal(t, [][]int{{1, 3}, {2, 4}, {5}}, giter.ToSlice(giter.ChunkBy(giter.Of(1, 3, 2, 4, 5), isEven)))
+		assert.Equal(t, [][]int{{1, 3}}, giter.ToSlice(giter.Limit(giter.ChunkBy(giter.Of(1, 3, 2, 4, 5), isEven), 1)))
+		assert.Empty(t, giter.ToSlice(giter.ChunkBy(giter.Empty[int](), isEven)))
+	})
+}
@@ -0,0 +1,132 @@
+package giter
+
+import (
+	"sort"
+
+	"github.com/dashjay/gog/internal/constraints"
+)
+
+// Range returns a seq of numbers from start (inclusive) to end (exclusive) by step, step must not be zero.
+// The sequence is decreasing when step is negative and stops before overflowing T.
+//
+// EXAMPLE:
+//
+//	giter.ToSlice(giter.Range(0, 10, 3)) 👉 [0, 3, 6, 9]
+//	giter.ToSlice(giter.Range(5, 0, -2)) 👉 [5, 3, 1]
+//	giter.ToSlice(giter.Range[float64](0, 1, 0.25)) 👉 [0, 0.25, 0.5, 0.75]
+func Range[T constraints.Number](start, end, step T) Seq[T] {
+	if step == 0 {
+		panic("giter: Range step must not be zero")
+	}
+	return func(yield func(T) bool) {
+		prev := start
+		for i := 0; ; i++ {
+			// multiply instead of accumulating, so float errors do not add up
+			v := start + T(i)*step
+			if i > 0 && (step > 0) != (v > prev) {
+				// overflowed
+				return
+			}
+			if (step > 0 && v >= end) || (step < 0 && v <= end) {
+				return
+			}
+			if !yield(v) {
+				return
+			}
+			prev = v
+		}
+	}
+}
+
+// Iterate returns an infinite seq of seed, f(seed), f(f(seed)), ...
+//
+// EXAMPLE:
+//
+//	giter.ToSlice(giter.Limit(giter.Iterate(1, func(x int) int { return x * 2 }), 4)) 👉 [1, 2, 4, 8]
+func Iterate[T any](seed T, f func(T) T) Seq[T] {
+	return func(yield func(T) bool) {
+		for v := seed; yield(v); v = f(v) {
+		}
+	}
+}
+
+// Generate returns a seq of the values returned by f, until f returns false.
+func Generate[T any](f func() (T, bool)) Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			v, ok := f()
+			if !ok || !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Cycle returns a seq that repeats seq forever, it is empty if seq is empty.
+//
+// EXAMPLE:
+//
+//	giter.ToSlice(giter.Limit(giter.Cycle(giter.Of(1, 2)), 5)) 👉 [1, 2, 1, 2, 1]
+func Cycle[T any](seq Seq[T]) Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			empty, stopped := true, false
+			seq(func(v T) bool {
+				empty = false
+				if !yield(v) {
+					stopped = true
+					return false
+				}
+				return true
+			})
+			if empty || stopped {
+				return
+			}
+		}
+	}
+}
+
+// Of returns a seq of values.
+func Of[T any](values ...T) Seq[T] {
+	return FromSlice(values)
+}
+
+// Once returns a seq of the single value v.
+func Once[T any](v T) Seq[T] {
+	return func(yield func(T) bool) {
+		yield(v)
+	}
+}
+
+// Empty returns a seq without any element.
+func Empty[T any]() Seq[T] {
+	return func(yield func(T) bool) {}
+}
+
+// FromMap returns a Seq2 of the key-value pairs in m, in the random order of map iteration.
+func FromMap[K comparable, V any](m map[K]V) Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, v := range m {
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// FromMapSorted returns a Seq2 of the key-value pairs in m, sorted by key.
+// The keys are collected and sorted each time the seq is iterated.
+func FromMapSorted[K constraints.Ordered, V any](m map[K]V) Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		keys := make([]K, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+		for _, k := range keys {
+			if !yield(k, m[k]) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package giter_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dashjay/gog/giter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerators(t *testing.T) {
+	t.Run("range", func(t *testing.T) {
+		assert.Equal(t, []int{0, 3, 6, 9}, giter.ToSlice(giter.Range(0, 10, 3)))
+		assert.Equal(t, _range(0, 10), giter.ToSlice(giter.Range(0, 10, 1)))
+		assert.Equal(t, []int{5, 3, 1}, giter.ToSlice(giter.Range(5, 0, -2)))
+		assert.Empty(t, giter.ToSlice(giter.Range(0, 0, 1)))
+		assert.Empty(t, giter.ToSlice(giter.Range(0, 10, -1)))
+		assert.Equal(t, []float64{0, 0.25, 0.5, 0.75}, giter.ToSlice(giter.Range[float64](0, 1, 0.25)))
+		assert.Len(t, giter.ToSlice(giter.Range[float64](0, 1, 0.1)), 10)
+		assert.Equal(t, []int{0, 1}, giter.ToSlice(giter.Limit(giter.Range(0, 10, 1), 2)))
+
+		// stops before overflowing
+		assert.Equal(t, []uint8{250, 253}, giter.ToSlice(giter.Range[uint8](250, 255, 3)))
+		assert.Equal(t, []int8{-100, -50, 0, 50, 100}, giter.ToSlice(giter.Range[int8](-100, math.MaxInt8, 50)))
+		assert.Equal(t, []int8{100, 0, -100}, giter.ToSlice(giter.Range[int8](100, math.MinInt8, -100)))
+
+		assert.Panics(t, func() { giter.Range(0, 1, 0) })
+	})
+
+	t.Run("iterate and generate", func(t *testing.T) {
+		assert.Equal(t, []int{1, 2, 4, 8}, giter.ToSlice(giter.Limit(giter.Iterate(1, func(x int) int { return x * 2 }), 4)))
+
+		n := 0
+		assert.Equal(t, []int{1, 2, 3}, giter.ToSlice(giter.Generate(func() (int, bool) {
+			n++
+			return n, n <= 3
+		})))
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		assert.Equal(t, []int{1, 2, 1, 2, 1}, giter.ToSlice(giter.Limit(giter.Cycle(giter.Of(1, 2)), 5)))
+		assert.Empty(t, giter.ToSlice(giter.Cycle(giter.Empty[int]())))
+	})
+
+	t.Run("of, once and empty", func(t *testing.T) {
+		assert.Equal(t, []int{1, 2, 3}, giter.ToSlice(giter.Of(1, 2, 3)))
+		assert.Empty(t, giter.ToSlice(giter.Of[int]()))
+		assert.Equal(t, []string{"a"}, giter.ToSlice(giter.Once("a")))
+		assert.Empty(t, giter.ToSlice(giter.Empty[string]()))
+	})
+
+	t.Run("from map", func(t *testing.T) {
+		m := map[string]int{"c": 3, "a": 1, "b": 2}
+		got := map[string]int{}
+		giter.FromMap(m)(func(k string, v int) bool {
+			got[k] = v
+			return true
+		})
+		assert.Equal(t, m, got)
+
+		var keys []string
+		var values []int
+		giter.FromMapSorted(m)(func(k string, v int) bool {
+			keys = append(keys, k)
+			values = append(values, v)
+			return k != "b"
+		})
+		assert.Equal(t, []string{"a", "b"}, keys)
+		assert.Equal(t, []int{1, 2}, values)
+	})
+}
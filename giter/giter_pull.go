@@ -0,0 +1,11 @@
+//go:build go1.23
+// +build go1.23
+
+package giter
+
+import "iter"
+
+// pull converts the push-style seq into a pull-style iterator, stop must be called when next is no longer used.
+func pull[T any](seq Seq[T]) (next func() (T, bool), stop func()) {
+	return iter.Pull(iter.Seq[T](seq))
+}
@@ -0,0 +1,64 @@
+//go:build !go1.23
+// +build !go1.23
+
+package giter
+
+// pull converts the push-style seq into a pull-style iterator, stop must be called when next is no longer used.
+//
+// Without iter.Pull, seq runs in a goroutine which only advances when next is called.
+func pull[T any](seq Seq[T]) (next func() (T, bool), stop func()) {
+	resume := make(chan struct{})
+	values := make(chan T)
+	stopped := make(chan struct{})
+	var panicValue any
+	var done, panicked bool
+
+	go func() {
+		defer close(values)
+		defer func() {
+			if r := recover(); r != nil {
+				panicValue, panicked = r, true
+			}
+		}()
+		select {
+		case <-resume:
+		case <-stopped:
+			return
+		}
+		seq(func(v T) bool {
+			values <- v
+			select {
+			case <-resume:
+				return true
+			case <-stopped:
+				return false
+			}
+		})
+	}()
+
+	next = func() (v T, ok bool) {
+		if done {
+			return
+		}
+		resume <- struct{}{}
+		v, ok = <-values
+		if !ok {
+			done = true
+			if panicked {
+				panic(panicValue)
+			}
+		}
+		return
+	}
+	stop = func() {
+		if done {
+			return
+		}
+		done = true
+		close(stopped)
+		// wait for the goroutine to exit, values yielded by a seq ignoring the stop are dropped
+		for range values {
+		}
+	}
+	return next, stop
+}
@@ -0,0 +1,196 @@
+package giter
+
+import (
+	"container/heap"
+
+	"github.com/dashjay/gog/internal/constraints"
+)
+
+// compare returns -1 if a < b, 1 if a > b and 0 otherwise.
+func compare[T constraints.Ordered](a, b T) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+type mergeItem[T any] struct {
+	value T
+	idx   int
+}
+
+// mergeHeap is a min heap of the head elements of the merged seqs,
+// ties are broken by the index of the seq so that the merge is stable.
+type mergeHeap[T any] struct {
+	items []mergeItem[T]
+	cmp   func(T, T) int
+}
+
+func (h *mergeHeap[T]) Len() int { return len(h.items) }
+
+func (h *mergeHeap[T]) Less(i, j int) bool {
+	if c := h.cmp(h.items[i].value, h.items[j].value); c != 0 {
+		return c < 0
+	}
+	return h.items[i].idx < h.items[j].idx
+}
+
+func (h *mergeHeap[T]) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *mergeHeap[T]) Push(x any) { h.items = append(h.items, x.(mergeItem[T])) }
+
+func (h *mergeHeap[T]) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
+
+// MergeSorted returns a seq merging the sorted seqs into one sorted seq lazily, using a k-way merge.
+// Equal elements are yielded in the order of seqs.
+//
+// EXAMPLE:
+//
+//	giter.ToSlice(giter.MergeSorted(giter.FromSlice([]int{1, 4}), giter.FromSlice([]int{2, 3, 5}))) 👉 [1, 2, 3, 4, 5]
+func MergeSorted[T constraints.Ordered](seqs ...Seq[T]) Seq[T] {
+	return MergeSortedBy(compare[T], seqs...)
+}
+
+// MergeSortedBy is like MergeSorted but the seqs are sorted by cmp,
+// cmp(a, b) should return a negative number when a < b, a positive number when a > b and zero when a == b.
+// Unlike the other *SortedBy functions which take cmp last, cmp comes first here because seqs is variadic.
+func MergeSortedBy[T any](cmp func(a, b T) int, seqs ...Seq[T]) Seq[T] {
+	return func(yield func(T) bool) {
+		nexts := make([]func() (T, bool), len(seqs))
+		h := &mergeHeap[T]{items: make([]mergeItem[T], 0, len(seqs)), cmp: cmp}
+		for i, seq := range seqs {
+			next, stop := pull(seq)
+			defer stop()
+			nexts[i] = next
+			if v, ok := next(); ok {
+				h.items = append(h.items, mergeItem[T]{value: v, idx: i})
+			}
+		}
+		heap.Init(h)
+		for h.Len() > 0 {
+			top := &h.items[0]
+			if !yield(top.value) {
+				return
+			}
+			if v, ok := nexts[top.idx](); ok {
+				top.value = v
+				heap.Fix(h, 0)
+			} else {
+				heap.Pop(h)
+			}
+		}
+	}
+}
+
+// UnionSorted returns a seq of the elements in sorted seq a or sorted seq b, the result is sorted.
+// Like std::set_union in C++, an element appearing m times in a and n times in b appears max(m, n) times.
+//
+// EXAMPLE:
+//
+//	giter.ToSlice(giter.UnionSorted(giter.FromSlice([]int{1, 2, 4}), giter.FromSlice([]int{2, 3}))) 👉 [1, 2, 3, 4]
+func UnionSorted[T constraints.Ordered](a, b Seq[T]) Seq[T] {
+	return UnionSortedBy(a, b, compare[T])
+}
+
+// UnionSortedBy is like UnionSorted but the seqs are sorted by cmp.
+func UnionSortedBy[T any](a, b Seq[T], cmp func(a, b T) int) Seq[T] {
+	return func(yield func(T) bool) {
+		next, stop := pull(b)
+		defer stop()
+		vb, okb := next()
+		stopped := false
+		a(func(va T) bool {
+			for okb && cmp(vb, va) < 0 {
+				if !yield(vb) {
+					stopped = true
+					return false
+				}
+				vb, okb = next()
+			}
+			if okb && cmp(vb, va) == 0 {
+				vb, okb = next()
+			}
+			if !yield(va) {
+				stopped = true
+				return false
+			}
+			return true
+		})
+		if stopped {
+			return
+		}
+		for ; okb; vb, okb = next() {
+			if !yield(vb) {
+				return
+			}
+		}
+	}
+}
+
+// IntersectSorted returns a seq of the elements in both sorted seq a and sorted seq b, the result is sorted.
+// Like std::set_intersection in C++, an element appearing m times in a and n times in b appears min(m, n) times.
+//
+// EXAMPLE:
+//
+//	giter.ToSlice(giter.IntersectSorted(giter.FromSlice([]int{1, 2, 4}), giter.FromSlice([]int{2, 3, 4}))) 👉 [2, 4]
+func IntersectSorted[T constraints.Ordered](a, b Seq[T]) Seq[T] {
+	return IntersectSortedBy(a, b, compare[T])
+}
+
+// IntersectSortedBy is like IntersectSorted but the seqs are sorted by cmp.
+func IntersectSortedBy[T any](a, b Seq[T], cmp func(a, b T) int) Seq[T] {
+	return func(yield func(T) bool) {
+		next, stop := pull(b)
+		defer stop()
+		vb, okb := next()
+		a(func(va T) bool {
+			for okb && cmp(vb, va) < 0 {
+				vb, okb = next()
+			}
+			if !okb {
+				return false
+			}
+			if cmp(vb, va) == 0 {
+				vb, okb = next()
+				return yield(va)
+			}
+			return true
+		})
+	}
+}
+
+// DifferenceSorted returns a seq of the elements in sorted seq a but not in sorted seq b, the result is sorted.
+// Like std::set_difference in C++, an element appearing m times in a and n times in b appears max(m-n, 0) times.
+//
+// EXAMPLE:
+//
+//	giter.ToSlice(giter.DifferenceSorted(giter.FromSlice([]int{1, 2, 4}), giter.FromSlice([]int{2, 3}))) 👉 [1, 4]
+func DifferenceSorted[T constraints.Ordered](a, b Seq[T]) Seq[T] {
+	return DifferenceSortedBy(a, b, compare[T])
+}
+
+// DifferenceSortedBy is like DifferenceSorted but the seqs are sorted by cmp.
+func DifferenceSortedBy[T any](a, b Seq[T], cmp func(a, b T) int) Seq[T] {
+	return func(yield func(T) bool) {
+		next, stop := pull(b)
+		defer stop()
+		vb, okb := next()
+		a(func(va T) bool {
+			for okb && cmp(vb, va) < 0 {
+				vb, okb = next()
+			}
+			if okb && cmp(vb, va) == 0 {
+				vb, okb = next()
+				return true
+			}
+			return yield(va)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package giter_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dashjay/gog/giter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSorted(t *testing.T) {
+	t.Run("merge sorted", func(t *testing.T) {
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7},
+			giter.ToSlice(giter.MergeSorted(
+				giter.FromSlice([]int{1, 4, 7}),
+				giter.FromSlice([]int{2, 5}),
+				giter.FromSlice([]int{}),
+				giter.FromSlice([]int{3, 6}),
+			)))
+		assert.Equal(t, []int{0, 1, 1}, giter.ToSlice(giter.Limit(giter.MergeSorted(
+			giter.FromSlice(_range(0, 100)), giter.FromSlice(_range(1, 100))), 3)))
+		assert.Empty(t, giter.ToSlice(giter.MergeSorted[int]()))
+
+		// equal elements keep the order of seqs
+		type kv struct {
+			k int
+			v string
+		}
+		byKey := func(a, b kv) int { return a.k - b.k }
+		assert.Equal(t, []kv{{1, "a"}, {1, "b"}, {2, "a"}, {2, "b"}, {3, "a"}},
+			giter.ToSlice(giter.MergeSortedBy(byKey,
+				giter.FromSlice([]kv{{1, "a"}, {2, "a"}, {3, "a"}}),
+				giter.FromSlice([]kv{{1, "b"}, {2, "b"}}),
+			)))
+	})
+
+	t.Run("union sorted", func(t *testing.T) {
+		assert.Equal(t, []int{1, 1, 2, 3, 4, 5},
+			giter.ToSlice(giter.UnionSorted(giter.FromSlice([]int{1, 1, 2, 4}), giter.FromSlice([]int{1, 3, 4, 5}))))
+		assert.Equal(t, []int{1, 2}, giter.ToSlice(giter.UnionSorted(giter.FromSlice([]int{}), giter.FromSlice([]int{1, 2}))))
+		assert.Equal(t, _range(0, 4),
+			giter.ToSlice(giter.Limit(giter.UnionSorted(giter.FromSlice(_range(50, 200)), giter.FromSlice(_range(0, 100))), 4)))
+
+		caseInsensitive := func(a, b string) int { return strings.Compare(strings.ToLower(a), strings.ToLower(b)) }
+		assert.Equal(t, []string{"a", "B", "c"},
+			giter.ToSlice(giter.UnionSortedBy(giter.FromSlice([]string{"a", "B"}), giter.FromSlice([]string{"b", "c"}), caseInsensitive)))
+	})
+
+	t.Run("intersect sorted", func(t *testing.T) {
+		assert.Equal(t, []int{1, 4},
+			giter.ToSlice(giter.IntersectSorted(giter.FromSlice([]int{1, 1, 2, 4}), giter.FromSlice([]int{1, 3, 4, 5}))))
+		assert.Empty(t, giter.ToSlice(giter.IntersectSorted(giter.FromSlice([]int{1, 2}), giter.FromSlice([]int{}))))
+		assert.Equal(t, _range(50, 60),
+			giter.ToSlice(giter.Limit(giter.IntersectSorted(giter.FromSlice(_range(0, 100)), giter.FromSlice(_range(50, 200))), 10)))
+
+		caseInsensitive := func(a, b string) int { return strings.Compare(strings.ToLower(a), strings.ToLower(b)) }
+		assert.Equal(t, []string{"B"},
+			giter.ToSlice(giter.IntersectSortedBy(giter.FromSlice([]string{"a", "B"}), giter.FromSlice([]string{"b", "c"}), caseInsensitive)))
+	})
+
+	t.Run("difference sorted", func(t *testing.T) {
+		assert.Equal(t, []int{1, 2},
+			giter.ToSlice(giter.DifferenceSorted(giter.FromSlice([]int{1, 1, 2, 4}), giter.FromSlice([]int{1, 3, 4, 5}))))
+		assert.Equal(t, []int{1, 2}, giter.ToSlice(giter.DifferenceSorted(giter.FromSlice([]int{1, 2}), giter.FromSlice([]int{}))))
+		assert.Equal(t, _range(0, 50),
+			giter.ToSlice(giter.Limit(giter.DifferenceSorted(giter.FromSlice(_range(0, 100)), giter.FromSlice(_range(50, 200))), 60)))
+
+		caseInsensitive := func(a, b string) int { return strings.Compare(strings.ToLower(a), strings.ToLower(b)) }
+		assert.Equal(t, []string{"a"},
+			giter.ToSlice(giter.DifferenceSortedBy(giter.FromSlice([]string{"a", "B"}), giter.FromSlice([]string{"b", "c"}), caseInsensitive)))
+	})
+}
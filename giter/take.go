@@ -0,0 +1,132 @@
+package giter
+
+import (
+	"github.com/dashjay/gog/internal/gassert"
+	"github.com/dashjay/gog/optional"
+)
+
+// TakeWhile returns a seq of the leading elements of seq satisfying f, it stops at the first element not satisfying f.
+//
+// EXAMPLE:
+//
+//	giter.ToSlice(giter.TakeWhile(giter.Of(1, 2, 5, 1), func(x int) bool { return x < 3 })) 👉 [1, 2]
+func TakeWhile[T any](seq Seq[T], f func(T) bool) Seq[T] {
+	return func(yield func(T) bool) {
+		seq(func(v T) bool {
+			return f(v) && yield(v)
+		})
+	}
+}
+
+// DropWhile returns a seq skipping the leading elements of seq satisfying f, the rest elements are all yielded.
+//
+// EXAMPLE:
+//
+//	giter.ToSlice(giter.DropWhile(giter.Of(1, 2, 5, 1), func(x int) bool { return x < 3 })) 👉 [5, 1]
+func DropWhile[T any](seq Seq[T], f func(T) bool) Seq[T] {
+	return func(yield func(T) bool) {
+		dropping := true
+		seq(func(v T) bool {
+			if dropping {
+				if f(v) {
+					return true
+				}
+				dropping = false
+			}
+			return yield(v)
+		})
+	}
+}
+
+// TakeLast returns a seq of the last n elements of seq, seq is consumed to the end before the first yield,
+// only n elements are kept in memory.
+//
+// EXAMPLE:
+//
+//	giter.ToSlice(giter.TakeLast(giter.Of(1, 2, 3, 4), 2)) 👉 [3, 4]
+func TakeLast[T any](seq Seq[T], n int) Seq[T] {
+	gassert.MustBePositive(n)
+	return func(yield func(T) bool) {
+		if n == 0 {
+			return
+		}
+		ring := make([]T, 0, n)
+		start := 0
+		seq(func(v T) bool {
+			if len(ring) < n {
+				ring = append(ring, v)
+				return true
+			}
+			ring[start] = v
+			start = (start + 1) % n
+			return true
+		})
+		for i := 0; i < len(ring); i++ {
+			if !yield(ring[(start+i)%len(ring)]) {
+				return
+			}
+		}
+	}
+}
+
+// SkipLast returns a seq of all elements of seq except the last n, each element is yielded
+// once n more elements have been read, only n elements are kept in memory.
+//
+// EXAMPLE:
+//
+//	giter.ToSlice(giter.SkipLast(giter.Of(1, 2, 3, 4), 1)) 👉 [1, 2, 3]
+func SkipLast[T any](seq Seq[T], n int) Seq[T] {
+	gassert.MustBePositive(n)
+	return func(yield func(T) bool) {
+		if n == 0 {
+			seq(yield)
+			return
+		}
+		ring := make([]T, 0, n)
+		pos := 0
+		seq(func(v T) bool {
+			if len(ring) < n {
+				ring = append(ring, v)
+				return true
+			}
+			oldest := ring[pos]
+			ring[pos] = v
+			pos = (pos + 1) % n
+			return yield(oldest)
+		})
+	}
+}
+
+// StepBy returns a seq of every n-th element of seq, starting from the first one, n must be positive.
+//
+// EXAMPLE:
+//
+//	giter.ToSlice(giter.StepBy(giter.Of(0, 1, 2, 3, 4, 5, 6), 3)) 👉 [0, 3, 6]
+func StepBy[T any](seq Seq[T], n int) Seq[T] {
+	if n <= 0 {
+		panic("giter: StepBy n must be positive")
+	}
+	return func(yield func(T) bool) {
+		i := 0
+		seq(func(v T) bool {
+			skip := i%n != 0
+			i++
+			return skip || yield(v)
+		})
+	}
+}
+
+// LastO return the last element from seq.
+func LastO[T any](seq Seq[T]) optional.O[T] {
+	return optional.FromValue2(Last(seq))
+}
+
+// Last return the last element from seq with a boolean representing whether it is at least one element in seq.
+func Last[T any](seq Seq[T]) (v T, hasOne bool) {
+	seq(func(t T) bool {
+		v = t
+		hasOne = true
+		return true
+	})
+	return
+}
@@ -0,0 +1,60 @@
+package giter_test
+
+import (
+	"testing"
+
+	"github.com/dashjay/gog/giter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTake(t *testing.T) {
+	lessThan3 := func(x int) bool { return x < 3 }
+
+	t.Run("take while and drop while", func(t *testing.T) {
+		assert.Equal(t, []int{1, 2}, giter.ToSlice(giter.TakeWhile(giter.Of(1, 2, 5, 1), lessThan3)))
+		assert.Empty(t, giter.ToSlice(giter.TakeWhile(giter.Of(5, 1), lessThan3)))
+		assert.Equal(t, []int{1}, giter.ToSlice(giter.Limit(giter.TakeWhile(giter.Of(1, 2, 5, 1), lessThan3), 1)))
+
+		assert.Equal(t, []int{5, 1}, giter.ToSlice(giter.DropWhile(giter.Of(1, 2, 5, 1), lessThan3)))
+		assert.Empty(t, giter.ToSlice(giter.DropWhile(giter.Of(1, 2), lessThan3)))
+		assert.Equal(t, []int{5}, giter.ToSlice(giter.Limit(giter.DropWhile(giter.Of(1, 2, 5, 1), lessThan3), 1)))
+
+		// seqs can be iterated again
+		seq := giter.DropWhile(giter.Of(1, 2, 5, 1), lessThan3)
+		assert.Equal(t, giter.ToSlice(seq), giter.ToSlice(seq))
+	})
+
+	t.Run("take last and skip last", func(t *testing.T) {
+		for n := 0; n < 12; n++ {
+			all := _range(0, 10)
+			split := len(all) - n
+			if split < 0 {
+				split = 0
+			}
+			assert.Equal(t, all[split:], append([]int{}, giter.ToSlice(giter.TakeLast(giter.FromSlice(all), n))...))
+			assert.Equal(t, all[:split], append([]int{}, giter.ToSlice(giter.SkipLast(giter.FromSlice(all), n))...))
+		}
+		assert.Equal(t, []int{7}, giter.ToSlice(giter.Limit(giter.TakeLast(giter.FromSlice(_range(0, 10)), 3), 1)))
+		assert.Equal(t, []int{0, 1}, giter.ToSlice(giter.Limit(giter.SkipLast(giter.FromSlice(_range(0, 10)), 3), 2)))
+		assert.Panics(t, func() { giter.TakeLast(giter.Of(1), -1) })
+		assert.Panics(t, func() { giter.SkipLast(giter.Of(1), -1) })
+	})
+
+	t.Run("step by", func(t *testing.T) {
+		assert.Equal(t, []int{0, 3, 6}, giter.ToSlice(giter.StepBy(giter.FromSlice(_range(0, 7)), 3)))
+		assert.Equal(t, _range(0, 7), giter.ToSlice(giter.StepBy(giter.FromSlice(_range(0, 7)), 1)))
+		assert.Equal(t, []int{0, 2}, giter.ToSlice(giter.Limit(giter.StepBy(giter.FromSlice(_range(0, 7)), 2), 2)))
+		assert.Panics(t, func() { giter.StepBy(giter.Of(1), 0) })
+	})
+
+	t.Run("last", func(t *testing.T) {
+		v, ok := giter.Last(giter.Of(1, 2, 3))
+		assert.True(t, ok)
+		assert.Equal(t, 3, v)
+		_, ok = giter.Last(giter.Empty[int]())
+		assert.False(t, ok)
+
+		assert.Equal(t, 3, giter.LastO(giter.Of(1, 2, 3)).Must())
+		assert.False(t, giter.LastO(giter.Empty[int]()).Ok())
+	})
+}
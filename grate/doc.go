@@ -0,0 +1,4 @@
+// Package grate provides some rate limiters like token bucket, sliding window log and sliding window counter.
+//
+// All limiters accept a gsync.Clock, so they can be tested with gsync.ManualClock without sleeping.
+package grate
@@ -0,0 +1,59 @@
+package grate
+
+import (
+	"time"
+
+	"github.com/dashjay/gog/gsync"
+)
+
+// KeyedLimiter maintains one Limiter per key, e.g. throttling per user,
+// limiters idle for longer than idleTimeout are evicted.
+type KeyedLimiter[K comparable, L Limiter] struct {
+	newLimiter  func(key K) L
+	idleTimeout time.Duration
+	limiters    *gsync.ExpiringMap[K, L]
+}
+
+// NewKeyedLimiter returns a new KeyedLimiter creating limiters by newLimiter,
+// idleTimeout <= 0 means limiters are never evicted, gsync.RealClock is used if clock is nil.
+// Close must be called to stop the background eviction.
+func NewKeyedLimiter[K comparable, L Limiter](newLimiter func(key K) L, idleTimeout time.Duration, clock gsync.Clock) *KeyedLimiter[K, L] {
+	return &KeyedLimiter[K, L]{
+		newLimiter:  newLimiter,
+		idleTimeout: idleTimeout,
+		limiters: gsync.NewExpiringMap(gsync.ExpiringMapConfig[K, L]{
+			DefaultTTL:      idleTimeout,
+			Sliding:         true,
+			JanitorInterval: idleTimeout,
+			Clock:           clockOrDefault(clock),
+		}),
+	}
+}
+
+// Get returns the limiter of key, it is created if not exists.
+func (k *KeyedLimiter[K, L]) Get(key K) L {
+	l, _, _ := k.limiters.LoadOrCompute(key, k.idleTimeout, func() (L, error) {
+		return k.newLimiter(key), nil
+	})
+	return l
+}
+
+// Allow is shorthand for AllowN(key, 1).
+func (k *KeyedLimiter[K, L]) Allow(key K) bool {
+	return k.Get(key).AllowN(1)
+}
+
+// AllowN reports whether n events of key may happen now.
+func (k *KeyedLimiter[K, L]) AllowN(key K, n int) bool {
+	return k.Get(key).AllowN(n)
+}
+
+// Len returns the number of limiters.
+func (k *KeyedLimiter[K, L]) Len() int {
+	return k.limiters.Len()
+}
+
+// Close stops the background eviction.
+func (k *KeyedLimiter[K, L]) Close() {
+	k.limiters.Close()
+}
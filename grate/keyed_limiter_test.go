@@ -0,0 +1,41 @@
+package grate_test
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/dashjay/gog/grate"
+	"github.com/dashjay/gog/gsync"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyedLimiter(t *testing.T) {
+	t.Parallel()
+
+	clock := gsync.NewManualClock(time.Now())
+	k := grate.NewKeyedLimiter(func(user string) *grate.TokenBucket {
+		return grate.NewTokenBucket(1, 2, clock)
+	}, time.Minute, clock)
+	defer k.Close()
+	// wait for the janitor
+	for clock.Waiters() != 1 {
+		runtime.Gosched()
+	}
+
+	assert.True(t, k.AllowN("alice", 2))
+	assert.False(t, k.Allow("alice"))
+	assert.True(t, k.Allow("bob"))
+	assert.Equal(t, 2, k.Len())
+	assert.Equal(t, float64(1), k.Get("bob").Tokens())
+
+	// bob is active, alice is idle
+	clock.Advance(40 * time.Second)
+	assert.True(t, k.Allow("bob"))
+	clock.Advance(30 * time.Second)
+	for k.Len() != 1 {
+		runtime.Gosched()
+	}
+	// alice gets a new full bucket
+	assert.True(t, k.AllowN("alice", 2))
+}
@@ -0,0 +1,35 @@
+package grate
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dashjay/gog/gsync"
+)
+
+var (
+	// ErrExceedsBurst is returned when the number of tokens requested can never be satisfied.
+	ErrExceedsBurst = errors.New("grate: requested tokens exceed the limiter's burst")
+	// ErrWouldExceedDeadline is returned when waiting for the tokens would exceed the deadline of the context.
+	ErrWouldExceedDeadline = errors.New("grate: waiting would exceed context deadline")
+)
+
+// Limiter is the common interface of all limiters.
+type Limiter interface {
+	// Allow reports whether an event may happen now.
+	Allow() bool
+	// AllowN reports whether n events may happen now.
+	AllowN(n int) bool
+}
+
+func clockOrDefault(clock gsync.Clock) gsync.Clock {
+	if clock == nil {
+		return gsync.RealClock
+	}
+	return clock
+}
+
+// durationFromTokens returns the duration to accumulate tokens at rate tokens per second.
+func durationFromTokens(tokens, rate float64) time.Duration {
+	return time.Duration(tokens / rate * float64(time.Second))
+}
@@ -0,0 +1,125 @@
+package grate
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dashjay/gog/gsync"
+)
+
+func mustBePositiveWindow(window time.Duration) {
+	if window <= 0 {
+		panic("grate: sliding window must be positive")
+	}
+}
+
+// SlidingWindowLog allows at most limit events in any window, it keeps the time of every allowed event,
+// so it is exact but uses O(limit) memory.
+type SlidingWindowLog struct {
+	limit  int
+	window time.Duration
+	clock  gsync.Clock
+
+	mu sync.Mutex
+	// log is a ring buffer of the time of allowed events, the oldest one at head.
+	log  []time.Time
+	head int
+	size int
+}
+
+// NewSlidingWindowLog returns a new SlidingWindowLog, gsync.RealClock is used if clock is nil.
+// It panics if window is not positive.
+func NewSlidingWindowLog(limit int, window time.Duration, clock gsync.Clock) *SlidingWindowLog {
+	mustBePositiveWindow(window)
+	if limit < 0 {
+		limit = 0
+	}
+	return &SlidingWindowLog{
+		limit:  limit,
+		window: window,
+		clock:  clockOrDefault(clock),
+		log:    make([]time.Time, limit),
+	}
+}
+
+// Allow is shorthand for AllowN(1).
+func (l *SlidingWindowLog) Allow() bool {
+	return l.AllowN(1)
+}
+
+// AllowN reports whether n events may happen now, the events are recorded if so.
+func (l *SlidingWindowLog) AllowN(n int) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock.Now()
+	// drop events out of the window
+	for l.size > 0 && !now.Before(l.log[l.head].Add(l.window)) {
+		l.head = (l.head + 1) % l.limit
+		l.size--
+	}
+	if l.size+n > l.limit {
+		return false
+	}
+	for i := 0; i < n; i++ {
+		l.log[(l.head+l.size)%l.limit] = now
+		l.size++
+	}
+	return true
+}
+
+// SlidingWindowCounter allows about limit events in any window, it approximates the sliding window
+// by weighting the count of the previous fixed window, so it uses O(1) memory.
+type SlidingWindowCounter struct {
+	limit  int
+	window time.Duration
+	clock  gsync.Clock
+
+	mu        sync.Mutex
+	curStart  time.Time
+	curCount  int
+	prevCount int
+}
+
+// NewSlidingWindowCounter returns a new SlidingWindowCounter, gsync.RealClock is used if clock is nil.
+// It panics if window is not positive.
+func NewSlidingWindowCounter(limit int, window time.Duration, clock gsync.Clock) *SlidingWindowCounter {
+	mustBePositiveWindow(window)
+	clock = clockOrDefault(clock)
+	return &SlidingWindowCounter{
+		limit:    limit,
+		window:   window,
+		clock:    clock,
+		curStart: clock.Now(),
+	}
+}
+
+// Allow is shorthand for AllowN(1).
+func (c *SlidingWindowCounter) Allow() bool {
+	return c.AllowN(1)
+}
+
+// AllowN reports whether n events may happen now, the events are counted if so.
+func (c *SlidingWindowCounter) AllowN(n int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.clock.Now()
+	elapsed := now.Sub(c.curStart)
+	if elapsed >= c.window {
+		windows := elapsed / c.window
+		if windows == 1 {
+			c.prevCount = c.curCount
+		} else {
+			c.prevCount = 0
+		}
+		c.curCount = 0
+		c.curStart = c.curStart.Add(windows * c.window)
+		elapsed = now.Sub(c.curStart)
+	}
+	weight := 1 - float64(elapsed)/float64(c.window)
+	estimated := float64(c.prevCount)*weight + float64(c.curCount)
+	if estimated+float64(n) > float64(c.limit) {
+		return false
+	}
+	c.curCount += n
+	return true
+}
@@ -0,0 +1,66 @@
+package grate_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dashjay/gog/grate"
+	"github.com/dashjay/gog/gsync"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlidingWindowLog(t *testing.T) {
+	t.Parallel()
+
+	clock := gsync.NewManualClock(time.Now())
+	l := grate.NewSlidingWindowLog(3, time.Second, clock)
+	assert.True(t, l.Allow())
+	clock.Advance(500 * time.Millisecond)
+	assert.True(t, l.AllowN(2))
+	assert.False(t, l.Allow())
+
+	// the first event slides out of the window
+	clock.Advance(500 * time.Millisecond)
+	assert.True(t, l.Allow())
+	assert.False(t, l.Allow())
+	assert.False(t, l.AllowN(4))
+
+	clock.Advance(time.Second)
+	assert.True(t, l.AllowN(3))
+
+	var limiter grate.Limiter = grate.NewSlidingWindowLog(0, time.Second, nil)
+	assert.False(t, limiter.Allow())
+
+	assert.Panics(t, func() { grate.NewSlidingWindowLog(1, 0, nil) })
+	assert.Panics(t, func() { grate.NewSlidingWindowLog(1, -time.Second, nil) })
+}
+
+func TestSlidingWindowCounter(t *testing.T) {
+	t.Parallel()
+
+	clock := gsync.NewManualClock(time.Now())
+	c := grate.NewSlidingWindowCounter(10, time.Second, clock)
+	assert.True(t, c.AllowN(10))
+	assert.False(t, c.Allow())
+
+	// half of the previous window is counted
+	clock.Advance(1500 * time.Millisecond)
+	assert.True(t, c.AllowN(5))
+	assert.False(t, c.Allow())
+
+	// the previous window has 5 events and 3/4 of it is counted
+	clock.Advance(750 * time.Millisecond)
+	assert.False(t, c.AllowN(7))
+	assert.True(t, c.AllowN(6))
+
+	// skip several windows
+	clock.Advance(3 * time.Second)
+	assert.True(t, c.AllowN(10))
+
+	var limiter grate.Limiter = grate.NewSlidingWindowCounter(1, time.Second, nil)
+	assert.True(t, limiter.Allow())
+	assert.False(t, limiter.Allow())
+
+	assert.Panics(t, func() { grate.NewSlidingWindowCounter(1, 0, nil) })
+	assert.Panics(t, func() { grate.NewSlidingWindowCounter(1, -time.Second, nil) })
+}
@@ -0,0 +1,185 @@
+package grate
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dashjay/gog/gsync"
+)
+
+// TokenBucket is a token bucket limiter, tokens are refilled at rate per second up to burst.
+type TokenBucket struct {
+	rate  float64
+	burst int
+	clock gsync.Clock
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket returns a new TokenBucket with a full bucket.
+// rate is the number of tokens refilled per second, burst is the capacity of the bucket,
+// gsync.RealClock is used if clock is nil.
+func NewTokenBucket(rate float64, burst int, clock gsync.Clock) *TokenBucket {
+	clock = clockOrDefault(clock)
+	return &TokenBucket{
+		rate:   rate,
+		burst:  burst,
+		clock:  clock,
+		tokens: float64(burst),
+		last:   clock.Now(),
+	}
+}
+
+// advanceLocked refills the tokens until now, b.mu must be held.
+func (b *TokenBucket) advanceLocked(now time.Time) {
+	if now.Before(b.last) {
+		return
+	}
+	elapsed := now.Sub(b.last)
+	b.last = now
+	if b.rate <= 0 {
+		return
+	}
+	b.tokens += elapsed.Seconds() * b.rate
+	if b.tokens > float64(b.burst) {
+		b.tokens = float64(b.burst)
+	}
+}
+
+// Allow is shorthand for AllowN(1).
+func (b *TokenBucket) Allow() bool {
+	return b.AllowN(1)
+}
+
+// AllowN reports whether n tokens are available now, the tokens are consumed if so.
+func (b *TokenBucket) AllowN(n int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advanceLocked(b.clock.Now())
+	if b.tokens < float64(n) {
+		return false
+	}
+	b.tokens -= float64(n)
+	return true
+}
+
+// Tokens returns the number of tokens available now.
+func (b *TokenBucket) Tokens() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advanceLocked(b.clock.Now())
+	return b.tokens
+}
+
+// Reservation holds tokens reserved by TokenBucket.Reserve, the event may happen after Delay.
+type Reservation struct {
+	bucket    *TokenBucket
+	ok        bool
+	tokens    int
+	timeToAct time.Time
+}
+
+// OK reports whether the tokens can be reserved, it is false if the tokens exceed the burst.
+func (r *Reservation) OK() bool {
+	return r.ok
+}
+
+// Delay returns how long the holder must wait before the event may happen, 0 means now.
+func (r *Reservation) Delay() time.Duration {
+	if !r.ok {
+		return 0
+	}
+	delay := r.timeToAct.Sub(r.bucket.clock.Now())
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
+
+// Cancel gives the reserved tokens back if the event has not happened yet,
+// so the following reservations can act earlier.
+func (r *Reservation) Cancel() {
+	if !r.ok {
+		return
+	}
+	b := r.bucket
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.clock.Now()
+	if !r.timeToAct.After(now) {
+		return
+	}
+	b.advanceLocked(now)
+	b.tokens += float64(r.tokens)
+	if b.tokens > float64(b.burst) {
+		b.tokens = float64(b.burst)
+	}
+	r.ok = false
+}
+
+// Reserve is shorthand for ReserveN(1).
+func (b *TokenBucket) Reserve() *Reservation {
+	return b.ReserveN(1)
+}
+
+// ReserveN reserves n tokens, the tokens may be borrowed from the future, the caller must wait for Delay before acting.
+// The Reservation is not OK if n exceeds the burst.
+func (b *TokenBucket) ReserveN(n int) *Reservation {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.clock.Now()
+	b.advanceLocked(now)
+	r := &Reservation{bucket: b, tokens: n}
+	if n > b.burst {
+		return r
+	}
+	b.tokens -= float64(n)
+	r.ok = true
+	r.timeToAct = now
+	if b.tokens < 0 {
+		if b.rate <= 0 {
+			b.tokens += float64(n)
+			r.ok = false
+			return r
+		}
+		r.timeToAct = now.Add(durationFromTokens(-b.tokens, b.rate))
+	}
+	return r
+}
+
+// Wait is shorthand for WaitN(ctx, 1).
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	return b.WaitN(ctx, 1)
+}
+
+// WaitN blocks until n tokens are available or ctx is done.
+// It returns ErrExceedsBurst if n exceeds the burst, ErrWouldExceedDeadline if ctx would expire before the tokens are available.
+func (b *TokenBucket) WaitN(ctx context.Context, n int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r := b.ReserveN(n)
+	if !r.OK() {
+		return ErrExceedsBurst
+	}
+	delay := r.Delay()
+	if delay == 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Sub(b.clock.Now()) < delay {
+		r.Cancel()
+		return ErrWouldExceedDeadline
+	}
+	timer := b.clock.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		r.Cancel()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,109 @@
+package grate_test
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/dashjay/gog/grate"
+	"github.com/dashjay/gog/gsync"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket(t *testing.T) {
+	t.Parallel()
+
+	t.Run("allow", func(t *testing.T) {
+		clock := gsync.NewManualClock(time.Now())
+		b := grate.NewTokenBucket(10, 5, clock)
+		assert.True(t, b.AllowN(5))
+		assert.False(t, b.Allow())
+
+		clock.Advance(100 * time.Millisecond)
+		assert.True(t, b.Allow())
+		assert.False(t, b.Allow())
+
+		// never more than burst
+		clock.Advance(time.Hour)
+		assert.Equal(t, float64(5), b.Tokens())
+		assert.False(t, b.AllowN(6))
+		assert.True(t, b.AllowN(5))
+	})
+
+	t.Run("reserve", func(t *testing.T) {
+		clock := gsync.NewManualClock(time.Now())
+		b := grate.NewTokenBucket(10, 1, clock)
+		r := b.Reserve()
+		assert.True(t, r.OK())
+		assert.Equal(t, time.Duration(0), r.Delay())
+
+		r = b.Reserve()
+		assert.True(t, r.OK())
+		assert.Equal(t, 100*time.Millisecond, r.Delay())
+		r2 := b.Reserve()
+		assert.Equal(t, 200*time.Millisecond, r2.Delay())
+
+		// cancel gives the tokens back
+		r2.Cancel()
+		assert.Equal(t, 200*time.Millisecond, b.Reserve().Delay())
+
+		assert.False(t, b.ReserveN(2).OK())
+		assert.Equal(t, time.Duration(0), b.ReserveN(2).Delay())
+
+		clock.Advance(time.Second)
+		assert.Equal(t, time.Duration(0), r.Delay())
+
+		zero := grate.NewTokenBucket(0, 1, clock)
+		assert.True(t, zero.Reserve().OK())
+		assert.False(t, zero.Reserve().OK())
+	})
+
+	t.Run("wait", func(t *testing.T) {
+		clock := gsync.NewManualClock(time.Now())
+		b := grate.NewTokenBucket(1, 1, clock)
+		assert.Nil(t, b.Wait(context.Background()))
+
+		done := make(chan error)
+		go func() {
+			done <- b.Wait(context.Background())
+		}()
+		for clock.Waiters() != 1 {
+			runtime.Gosched()
+		}
+		clock.Advance(time.Second)
+		assert.Nil(t, <-done)
+
+		assert.Equal(t, grate.ErrExceedsBurst, b.WaitN(context.Background(), 2))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		assert.Equal(t, grate.ErrWouldExceedDeadline, b.Wait(ctx))
+
+		// the deadline is compared with the time of the clock
+		ctx, cancel = context.WithDeadline(context.Background(), clock.Now().Add(500*time.Millisecond))
+		defer cancel()
+		assert.Equal(t, grate.ErrWouldExceedDeadline, b.Wait(ctx))
+
+		ctx, cancel = context.WithCancel(context.Background())
+		go func() {
+			done <- b.Wait(ctx)
+		}()
+		for clock.Waiters() != 1 {
+			runtime.Gosched()
+		}
+		cancel()
+		assert.Equal(t, context.Canceled, <-done)
+		assert.Equal(t, context.Canceled, b.Wait(ctx))
+
+		// the canceled reservation gave its token back
+		clock.Advance(time.Second)
+		assert.True(t, b.Allow())
+	})
+
+	t.Run("real clock", func(t *testing.T) {
+		b := grate.NewTokenBucket(1000, 1, nil)
+		assert.Nil(t, b.Wait(context.Background()))
+		assert.Nil(t, b.Wait(context.Background()))
+	})
+}
@@ -0,0 +1,39 @@
+//go:build !go1.19
+// +build !go1.19
+
+package gsync
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// AtomicPointer is an atomic pointer of type *T.
+// Before Go1.19 atomic.Pointer does not exist, so it is implemented with unsafe.Pointer.
+// The zero value is a nil *T.
+type AtomicPointer[T any] struct {
+	// mention *T in a field to disallow conversion between AtomicPointer types, like atomic.Pointer does.
+	_ [0]*T
+	v unsafe.Pointer
+	_ noCopy
+}
+
+// Load atomically loads and returns the value stored in x.
+func (x *AtomicPointer[T]) Load() *T {
+	return (*T)(atomic.LoadPointer(&x.v))
+}
+
+// Store atomically stores val into x.
+func (x *AtomicPointer[T]) Store(val *T) {
+	atomic.StorePointer(&x.v, unsafe.Pointer(val))
+}
+
+// Swap atomically stores new into x and returns the previous value.
+func (x *AtomicPointer[T]) Swap(new *T) (old *T) {
+	return (*T)(atomic.SwapPointer(&x.v, unsafe.Pointer(new)))
+}
+
+// CompareAndSwap executes the compare-and-swap operation for x.
+func (x *AtomicPointer[T]) CompareAndSwap(old, new *T) (swapped bool) {
+	return atomic.CompareAndSwapPointer(&x.v, unsafe.Pointer(old), unsafe.Pointer(new))
+}
@@ -0,0 +1,33 @@
+//go:build go1.19
+// +build go1.19
+
+package gsync
+
+import "sync/atomic"
+
+// AtomicPointer is an atomic pointer of type *T.
+// Since Go1.19 it wraps atomic.Pointer, it keeps the same interface as the implementation for lower go versions.
+// The zero value is a nil *T.
+type AtomicPointer[T any] struct {
+	v atomic.Pointer[T]
+}
+
+// Load atomically loads and returns the value stored in x.
+func (x *AtomicPointer[T]) Load() *T {
+	return x.v.Load()
+}
+
+// Store atomically stores val into x.
+func (x *AtomicPointer[T]) Store(val *T) {
+	x.v.Store(val)
+}
+
+// Swap atomically stores new into x and returns the previous value.
+func (x *AtomicPointer[T]) Swap(new *T) (old *T) {
+	return x.v.Swap(new)
+}
+
+// CompareAndSwap executes the compare-and-swap operation for x.
+func (x *AtomicPointer[T]) CompareAndSwap(old, new *T) (swapped bool) {
+	return x.v.CompareAndSwap(old, new)
+}
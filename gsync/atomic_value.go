@@ -0,0 +1,57 @@
+package gsync
+
+import "sync/atomic"
+
+// atomicBox wraps the value stored in atomic.Value,
+// so that atomic.Value always sees the same concrete type even if T is an interface type holding nil or different types.
+type atomicBox[T comparable] struct {
+	v T
+}
+
+// AtomicValue is a type-safe wrapper for atomic.Value.
+// The zero value is ready to use and Load returns the zero value of T before any Store.
+type AtomicValue[T comparable] struct {
+	v atomic.Value
+	_ noCopy
+}
+
+// NewAtomicValue returns a new AtomicValue storing v.
+func NewAtomicValue[T comparable](v T) *AtomicValue[T] {
+	a := &AtomicValue[T]{}
+	a.Store(v)
+	return a
+}
+
+// Load atomically loads and returns the stored value.
+func (a *AtomicValue[T]) Load() (v T) {
+	if box, ok := a.v.Load().(atomicBox[T]); ok {
+		v = box.v
+	}
+	return
+}
+
+// Store atomically stores v.
+func (a *AtomicValue[T]) Store(v T) {
+	a.v.Store(atomicBox[T]{v: v})
+}
+
+// Swap atomically stores new and returns the previous value.
+func (a *AtomicValue[T]) Swap(new T) (old T) {
+	if box, ok := a.v.Swap(atomicBox[T]{v: new}).(atomicBox[T]); ok {
+		old = box.v
+	}
+	return
+}
+
+// CompareAndSwap executes the compare-and-swap operation for the value.
+func (a *AtomicValue[T]) CompareAndSwap(old, new T) (swapped bool) {
+	if a.v.CompareAndSwap(atomicBox[T]{v: old}, atomicBox[T]{v: new}) {
+		return true
+	}
+	// nothing stored yet, the current value is the zero value
+	var zero T
+	if old != zero {
+		return false
+	}
+	return a.v.CompareAndSwap(nil, atomicBox[T]{v: new})
+}
@@ -0,0 +1,29 @@
+//go:build go1.20
+// +build go1.20
+
+package gsync_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dashjay/gog/gsync"
+	"github.com/stretchr/testify/assert"
+)
+
+// interface types satisfy comparable since go1.20
+func TestAtomicValueInterface(t *testing.T) {
+	t.Parallel()
+
+	var a gsync.AtomicValue[error]
+	assert.Nil(t, a.Load())
+	someErr := errors.New("some error")
+	a.Store(someErr)
+	assert.Equal(t, someErr, a.Load())
+	// nil and different concrete types do not panic
+	a.Store(nil)
+	assert.Nil(t, a.Load())
+	a.Store(&gsync.PanicError{})
+	assert.True(t, a.CompareAndSwap(a.Load(), nil))
+	assert.Nil(t, a.Load())
+}
@@ -0,0 +1,98 @@
+package gsync_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/dashjay/gog/gsync"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtomicValue(t *testing.T) {
+	t.Parallel()
+
+	t.Run("zero value", func(t *testing.T) {
+		var a gsync.AtomicValue[int]
+		assert.Equal(t, 0, a.Load())
+		assert.False(t, a.CompareAndSwap(1, 2))
+		assert.True(t, a.CompareAndSwap(0, 1))
+		assert.Equal(t, 1, a.Load())
+	})
+
+	t.Run("load store swap cas", func(t *testing.T) {
+		a := gsync.NewAtomicValue("a")
+		assert.Equal(t, "a", a.Load())
+		a.Store("b")
+		assert.Equal(t, "b", a.Swap("c"))
+		assert.False(t, a.CompareAndSwap("b", "d"))
+		assert.True(t, a.CompareAndSwap("c", "d"))
+		assert.Equal(t, "d", a.Load())
+
+		var b gsync.AtomicValue[string]
+		assert.Equal(t, "", b.Swap("a"))
+		assert.Equal(t, "a", b.Load())
+	})
+
+	t.Run("concurrent cas", func(t *testing.T) {
+		var a gsync.AtomicValue[int]
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					for {
+						old := a.Load()
+						if a.CompareAndSwap(old, old+1) {
+							break
+						}
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 1000, a.Load())
+	})
+}
+
+func TestAtomicPointer(t *testing.T) {
+	t.Parallel()
+
+	var p gsync.AtomicPointer[int]
+	assert.Nil(t, p.Load())
+	a, b := 1, 2
+	p.Store(&a)
+	assert.Equal(t, &a, p.Load())
+	assert.Equal(t, &a, p.Swap(&b))
+	assert.False(t, p.CompareAndSwap(&a, nil))
+	assert.True(t, p.CompareAndSwap(&b, &a))
+	assert.Equal(t, 1, *p.Load())
+}
+
+func TestStripedCounter(t *testing.T) {
+	t.Parallel()
+
+	c := gsync.NewStripedCounter()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				c.Inc()
+			}
+			c.Add(10)
+			c.Dec()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(10*(1000+10-1)), c.Load())
+	assert.Equal(t, int64(10*(1000+10-1)), c.Reset())
+	assert.Equal(t, int64(0), c.Load())
+
+	var zero gsync.StripedCounter
+	assert.Equal(t, int64(0), zero.Load())
+	zero.Inc()
+	zero.Add(2)
+	assert.Equal(t, int64(3), zero.Load())
+}
@@ -0,0 +1,226 @@
+package gsync
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrPoolClosed is returned by BoundedPool.Get after the pool is closed.
+var ErrPoolClosed = errors.New("gsync: pool is closed")
+
+// BoundedPoolConfig configures a BoundedPool, only New is required.
+type BoundedPoolConfig[T any] struct {
+	// New creates a new object when there is no idle object.
+	New func() (T, error)
+	// Reset is called on Put before the object becomes idle.
+	Reset func(T)
+	// Validate is called on Get for an idle object, the object is destroyed if Validate returns false.
+	Validate func(T) bool
+	// Destroy is called when an object is dropped by the pool, e.g. invalid, expired, discarded or the pool is closed.
+	Destroy func(T)
+	// MaxSize is the maximum number of objects (in use and idle), MaxSize <= 0 means no limit.
+	MaxSize int
+	// IdleTimeout is the maximum duration an object can stay idle, IdleTimeout <= 0 means no expiry.
+	IdleTimeout time.Duration
+	// Clock is used for idle expiry, RealClock is used if nil.
+	Clock Clock
+}
+
+// BoundedPoolStats is the statistics of a BoundedPool.
+type BoundedPoolStats struct {
+	// Hits is the number of Get served by an idle object.
+	Hits uint64
+	// Misses is the number of Get which created a new object.
+	Misses uint64
+	// InUse is the number of objects got but not put back yet.
+	InUse int
+	// Idle is the number of idle objects in the pool.
+	Idle int
+}
+
+type idleObject[T any] struct {
+	value   T
+	idledAt time.Time
+}
+
+// BoundedPool is an object pool with a maximum size and lifecycle hooks.
+// Unlike SyncPool, idle objects are never dropped by GC, so it is suitable for pooling connections and large buffers.
+type BoundedPool[T any] struct {
+	cfg BoundedPoolConfig[T]
+	// sem holds a token for each object in use, nil if the pool is unbounded.
+	sem chan struct{}
+
+	mu     sync.Mutex
+	idle   []idleObject[T] // the oldest idle object comes first
+	stats  BoundedPoolStats
+	closed bool
+}
+
+// NewBoundedPool creates a new BoundedPool with cfg.
+func NewBoundedPool[T any](cfg BoundedPoolConfig[T]) *BoundedPool[T] {
+	if cfg.New == nil {
+		panic("gsync: BoundedPoolConfig.New must not be nil")
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = RealClock
+	}
+	p := &BoundedPool[T]{cfg: cfg}
+	if cfg.MaxSize > 0 {
+		p.sem = make(chan struct{}, cfg.MaxSize)
+	}
+	return p
+}
+
+// Get returns an idle object or creates a new one,
+// it blocks until an object is put back if MaxSize objects are in use, and returns ctx.Err() if ctx is done first.
+func (p *BoundedPool[T]) Get(ctx context.Context) (val T, err error) {
+	if p.sem != nil {
+		select {
+		case p.sem <- struct{}{}:
+		default:
+			select {
+			case p.sem <- struct{}{}:
+			case <-ctx.Done():
+				return val, ctx.Err()
+			}
+		}
+	}
+
+	// the token is released unless an object is handed out, also when a hook panics
+	handedOut := false
+	defer func() {
+		if !handedOut {
+			p.release()
+		}
+	}()
+
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return val, ErrPoolClosed
+		}
+		if len(p.idle) == 0 {
+			break
+		}
+		obj := p.idle[len(p.idle)-1]
+		p.idle[len(p.idle)-1] = idleObject[T]{}
+		p.idle = p.idle[:len(p.idle)-1]
+		p.mu.Unlock()
+
+		// hooks are called without holding the lock, they may be slow, e.g. pinging a connection
+		if p.expired(obj, p.cfg.Clock.Now()) || (p.cfg.Validate != nil && !p.cfg.Validate(obj.value)) {
+			p.destroy(obj.value)
+			continue
+		}
+		p.mu.Lock()
+		p.stats.Hits++
+		p.stats.InUse++
+		p.mu.Unlock()
+		handedOut = true
+		return obj.value, nil
+	}
+	p.stats.Misses++
+	p.mu.Unlock()
+
+	val, err = p.cfg.New()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	p.mu.Lock()
+	p.stats.InUse++
+	p.mu.Unlock()
+	handedOut = true
+	return val, nil
+}
+
+// Put resets x and puts it back to the pool as an idle object, x is destroyed if the pool is closed.
+func (p *BoundedPool[T]) Put(x T) {
+	if p.cfg.Reset != nil {
+		reset := false
+		defer func() {
+			if !reset {
+				// Reset panicked, x is discarded so that its token is not leaked
+				p.Discard(x)
+			}
+		}()
+		p.cfg.Reset(x)
+		reset = true
+	}
+	p.mu.Lock()
+	p.stats.InUse--
+	if p.closed {
+		p.mu.Unlock()
+		p.release()
+		p.destroy(x)
+		return
+	}
+	now := p.cfg.Clock.Now()
+	// prune the expired idle objects from the oldest one
+	n := 0
+	for n < len(p.idle) && p.expired(p.idle[n], now) {
+		n++
+	}
+	dropped := make([]T, 0, n)
+	for i := 0; i < n; i++ {
+		dropped = append(dropped, p.idle[i].value)
+	}
+	p.idle = append(p.idle[:0], p.idle[n:]...)
+	p.idle = append(p.idle, idleObject[T]{value: x, idledAt: now})
+	p.mu.Unlock()
+	p.release()
+	p.destroy(dropped...)
+}
+
+// Discard tells the pool that x got by Get is broken and should not be reused, x is destroyed.
+func (p *BoundedPool[T]) Discard(x T) {
+	p.mu.Lock()
+	p.stats.InUse--
+	p.mu.Unlock()
+	p.release()
+	p.destroy(x)
+}
+
+// Stats returns the statistics of the pool.
+func (p *BoundedPool[T]) Stats() BoundedPoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := p.stats
+	stats.Idle = len(p.idle)
+	return stats
+}
+
+// Close destroys all idle objects, Get returns ErrPoolClosed after Close,
+// and objects in use are destroyed when they are put back.
+func (p *BoundedPool[T]) Close() {
+	p.mu.Lock()
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	p.mu.Unlock()
+	for _, obj := range idle {
+		p.destroy(obj.value)
+	}
+}
+
+func (p *BoundedPool[T]) expired(obj idleObject[T], now time.Time) bool {
+	return p.cfg.IdleTimeout > 0 && now.Sub(obj.idledAt) >= p.cfg.IdleTimeout
+}
+
+func (p *BoundedPool[T]) release() {
+	if p.sem != nil {
+		<-p.sem
+	}
+}
+
+func (p *BoundedPool[T]) destroy(xs ...T) {
+	if p.cfg.Destroy == nil {
+		return
+	}
+	for _, x := range xs {
+		p.cfg.Destroy(x)
+	}
+}
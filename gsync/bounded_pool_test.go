@@ -0,0 +1,243 @@
+package gsync_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dashjay/gog/gsync"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoundedPool(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reuse and stats", func(t *testing.T) {
+		var created int32
+		p := gsync.NewBoundedPool(gsync.BoundedPoolConfig[*bytes.Buffer]{
+			New: func() (*bytes.Buffer, error) {
+				atomic.AddInt32(&created, 1)
+				return new(bytes.Buffer), nil
+			},
+			Reset: func(b *bytes.Buffer) {
+				b.Reset()
+			},
+			MaxSize: 2,
+		})
+		b, err := p.Get(context.Background())
+		assert.Nil(t, err)
+		b.WriteString("secret")
+		assert.Equal(t, gsync.BoundedPoolStats{Misses: 1, InUse: 1}, p.Stats())
+		p.Put(b)
+		assert.Equal(t, gsync.BoundedPoolStats{Misses: 1, Idle: 1}, p.Stats())
+
+		b, err = p.Get(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 0, b.Len())
+		assert.Equal(t, gsync.BoundedPoolStats{Hits: 1, Misses: 1, InUse: 1}, p.Stats())
+		p.Put(b)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&created))
+	})
+
+	t.Run("blocking get", func(t *testing.T) {
+		p := gsync.NewBoundedPool(gsync.BoundedPoolConfig[int]{
+			New: func() (int, error) {
+				return 1, nil
+			},
+			MaxSize: 1,
+		})
+		v, err := p.Get(context.Background())
+		assert.Nil(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		_, err = p.Get(ctx)
+		assert.Equal(t, context.DeadlineExceeded, err)
+
+		got := make(chan int)
+		go func() {
+			v, err := p.Get(context.Background())
+			assert.Nil(t, err)
+			got <- v
+		}()
+		p.Put(v)
+		assert.Equal(t, 1, <-got)
+		assert.Equal(t, gsync.BoundedPoolStats{Hits: 1, Misses: 1, InUse: 1}, p.Stats())
+	})
+
+	t.Run("validate discard and new error", func(t *testing.T) {
+		var destroyed []int
+		next := 0
+		newErr := errors.New("new error")
+		p := gsync.NewBoundedPool(gsync.BoundedPoolConfig[int]{
+			New: func() (int, error) {
+				next++
+				if next == 4 {
+					return 0, newErr
+				}
+				return next, nil
+			},
+			Validate: func(i int) bool {
+				return i != 1
+			},
+			Destroy: func(i int) {
+				destroyed = append(destroyed, i)
+			},
+			MaxSize: 1,
+		})
+		v, _ := p.Get(context.Background())
+		p.Put(v)
+		// 1 is invalid
+		v, _ = p.Get(context.Background())
+		assert.Equal(t, 2, v)
+		assert.Equal(t, []int{1}, destroyed)
+		p.Discard(v)
+		assert.Equal(t, []int{1, 2}, destroyed)
+
+		v, _ = p.Get(context.Background())
+		assert.Equal(t, 3, v)
+		p.Discard(v)
+		_, err := p.Get(context.Background())
+		assert.Equal(t, newErr, err)
+		// the token is released on error
+		v, err = p.Get(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 5, v)
+		assert.Equal(t, gsync.BoundedPoolStats{Misses: 5, InUse: 1}, p.Stats())
+	})
+
+	t.Run("new panics", func(t *testing.T) {
+		next := 0
+		p := gsync.NewBoundedPool(gsync.BoundedPoolConfig[int]{
+			New: func() (int, error) {
+				next++
+				if next == 1 {
+					panic("boom")
+				}
+				return next, nil
+			},
+			MaxSize: 1,
+		})
+		assert.PanicsWithValue(t, "boom", func() {
+			_, _ = p.Get(context.Background())
+		})
+		// the token is released on panic
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		v, err := p.Get(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, v)
+		assert.Equal(t, gsync.BoundedPoolStats{Misses: 2, InUse: 1}, p.Stats())
+	})
+
+	t.Run("validate and reset panic", func(t *testing.T) {
+		var destroyed []int
+		next := 0
+		p := gsync.NewBoundedPool(gsync.BoundedPoolConfig[int]{
+			New: func() (int, error) {
+				next++
+				return next, nil
+			},
+			Validate: func(i int) bool {
+				if i == 1 {
+					panic("validate")
+				}
+				return true
+			},
+			Reset: func(i int) {
+				if i == 2 {
+					panic("reset")
+				}
+			},
+			Destroy: func(i int) {
+				destroyed = append(destroyed, i)
+			},
+			MaxSize: 1,
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		v, _ := p.Get(ctx)
+		p.Put(v)
+		assert.PanicsWithValue(t, "validate", func() {
+			_, _ = p.Get(ctx)
+		})
+		// the token is released on panic
+		v, err := p.Get(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, v)
+		assert.Equal(t, gsync.BoundedPoolStats{Misses: 2, InUse: 1}, p.Stats())
+
+		assert.PanicsWithValue(t, "reset", func() {
+			p.Put(v)
+		})
+		// 2 is discarded and its token is released
+		assert.Equal(t, []int{2}, destroyed)
+		assert.Equal(t, gsync.BoundedPoolStats{Misses: 2}, p.Stats())
+		v, err = p.Get(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, v)
+	})
+
+	t.Run("idle expiry", func(t *testing.T) {
+		clock := gsync.NewManualClock(time.Now())
+		var destroyed []int
+		next := 0
+		p := gsync.NewBoundedPool(gsync.BoundedPoolConfig[int]{
+			New: func() (int, error) {
+				next++
+				return next, nil
+			},
+			Destroy: func(i int) {
+				destroyed = append(destroyed, i)
+			},
+			IdleTimeout: time.Second,
+			Clock:       clock,
+		})
+		v1, _ := p.Get(context.Background())
+		v2, _ := p.Get(context.Background())
+		p.Put(v1)
+		clock.Advance(time.Second)
+		// v1 is expired and pruned on Put
+		p.Put(v2)
+		assert.Equal(t, []int{1}, destroyed)
+		assert.Equal(t, 1, p.Stats().Idle)
+
+		clock.Advance(time.Second)
+		// v2 is expired on Get
+		v, _ := p.Get(context.Background())
+		assert.Equal(t, 3, v)
+		assert.Equal(t, []int{1, 2}, destroyed)
+		p.Put(v)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		var destroyed int32
+		p := gsync.NewBoundedPool(gsync.BoundedPoolConfig[int]{
+			New: func() (int, error) {
+				return 1, nil
+			},
+			Destroy: func(int) {
+				atomic.AddInt32(&destroyed, 1)
+			},
+			MaxSize: 2,
+		})
+		v1, _ := p.Get(context.Background())
+		v2, _ := p.Get(context.Background())
+		p.Put(v1)
+		p.Close()
+		assert.Equal(t, int32(1), atomic.LoadInt32(&destroyed))
+		_, err := p.Get(context.Background())
+		assert.Equal(t, gsync.ErrPoolClosed, err)
+		p.Put(v2)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&destroyed))
+		assert.Equal(t, gsync.BoundedPoolStats{Misses: 2}, p.Stats())
+	})
+
+	assert.Panics(t, func() {
+		gsync.NewBoundedPool(gsync.BoundedPoolConfig[int]{})
+	})
+}
@@ -0,0 +1,221 @@
+package gsync
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy decides what Broadcaster.Publish does when the buffer of a subscriber is full.
+type OverflowPolicy int
+
+const (
+	// OverflowDrop drops the new value for the subscriber.
+	OverflowDrop OverflowPolicy = iota
+	// OverflowBlock blocks Publish until the subscriber receives, unsubscribes or the broadcaster is closed.
+	OverflowBlock
+	// OverflowLatest drops the oldest buffered value, so the subscriber always gets the latest values.
+	OverflowLatest
+)
+
+// Subscription is a subscriber of a Broadcaster.
+type Subscription[T any] struct {
+	// C receives the published values, it is closed after Unsubscribe or Broadcaster.Close.
+	C <-chan T
+
+	ch chan T
+	// mu serializes the sends to ch with closing it, closed reports whether ch is closed.
+	mu          sync.Mutex
+	closed      bool
+	policy      OverflowPolicy
+	broadcaster *Broadcaster[T]
+	done        chan struct{}
+	once        sync.Once
+	dropped     uint64
+}
+
+// Unsubscribe removes the subscription from the broadcaster and closes C.
+func (s *Subscription[T]) Unsubscribe() {
+	s.once.Do(func() {
+		// wake up the publisher blocked on this subscription first
+		close(s.done)
+		b := s.broadcaster
+		b.mu.Lock()
+		delete(b.subs, s)
+		b.mu.Unlock()
+		s.close()
+	})
+}
+
+// close closes ch once no value is being sent to it.
+func (s *Subscription[T]) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
+
+// Dropped returns the number of values dropped for the subscription because of overflow.
+func (s *Subscription[T]) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// deliver sends v to the subscription according to its policy, it is called without holding b.mu,
+// so a blocked send does not block the other methods of the broadcaster.
+func (s *Subscription[T]) deliver(v T, closing <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	switch s.policy {
+	case OverflowBlock:
+		select {
+		case s.ch <- v:
+		case <-s.done:
+		case <-closing:
+		}
+	case OverflowLatest:
+		for {
+			select {
+			case s.ch <- v:
+				return
+			default:
+			}
+			select {
+			case <-s.ch:
+				atomic.AddUint64(&s.dropped, 1)
+			default:
+			}
+		}
+	default:
+		select {
+		case s.ch <- v:
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+		}
+	}
+}
+
+// Broadcaster publishes values to all subscribers, each subscriber has its own buffer and overflow policy,
+// so a slow subscriber does not lose events of others unless it uses OverflowBlock.
+type Broadcaster[T any] struct {
+	mu        sync.Mutex
+	subs      map[*Subscription[T]]struct{}
+	closed    bool
+	closing   chan struct{}
+	closeOnce sync.Once
+
+	// replay is a ring buffer of the last published values, the oldest one at replayHead.
+	replay     []T
+	replayHead int
+	replayLen  int
+}
+
+// NewBroadcaster returns a new Broadcaster, new subscribers receive the last replay values published before they subscribe.
+func NewBroadcaster[T any](replay int) *Broadcaster[T] {
+	if replay < 0 {
+		replay = 0
+	}
+	return &Broadcaster[T]{
+		subs:    make(map[*Subscription[T]]struct{}),
+		closing: make(chan struct{}),
+		replay:  make([]T, replay),
+	}
+}
+
+// Subscribe returns a new Subscription whose channel has a buffer of size buffer,
+// the buffer is at least 1 for OverflowDrop and OverflowLatest.
+// If the broadcaster is closed, the channel of the returned Subscription is closed.
+func (b *Broadcaster[T]) Subscribe(buffer int, policy OverflowPolicy) *Subscription[T] {
+	if buffer < 1 && policy != OverflowBlock {
+		buffer = 1
+	}
+	if buffer < 0 {
+		buffer = 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// the replayed values always fit in the channel
+	ch := make(chan T, buffer+b.replayLen)
+	s := &Subscription[T]{C: ch, ch: ch, policy: policy, broadcaster: b, done: make(chan struct{})}
+	for i := 0; i < b.replayLen; i++ {
+		ch <- b.replay[(b.replayHead+i)%len(b.replay)]
+	}
+	if b.closed {
+		s.closed = true
+		close(ch)
+		return s
+	}
+	b.subs[s] = struct{}{}
+	return s
+}
+
+// SubscribeFunc is like Subscribe but calls fn for each value in a dedicated goroutine,
+// the goroutine exits after Unsubscribe or Close.
+func (b *Broadcaster[T]) SubscribeFunc(buffer int, policy OverflowPolicy, fn func(T)) *Subscription[T] {
+	s := b.Subscribe(buffer, policy)
+	go func() {
+		for v := range s.ch {
+			fn(v)
+		}
+	}()
+	return s
+}
+
+// Publish sends v to all subscribers, it reports false if the broadcaster is closed.
+func (b *Broadcaster[T]) Publish(v T) bool {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return false
+	}
+	if len(b.replay) > 0 {
+		if b.replayLen < len(b.replay) {
+			b.replay[(b.replayHead+b.replayLen)%len(b.replay)] = v
+			b.replayLen++
+		} else {
+			b.replay[b.replayHead] = v
+			b.replayHead = (b.replayHead + 1) % len(b.replay)
+		}
+	}
+	subs := make([]*Subscription[T], 0, len(b.subs))
+	for s := range b.subs {
+		subs = append(subs, s)
+	}
+	b.mu.Unlock()
+
+	for _, s := range subs {
+		s.deliver(v, b.closing)
+	}
+	return true
+}
+
+// Len returns the number of subscribers.
+func (b *Broadcaster[T]) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+// Close closes the channels of all subscribers, Publish has no effect after Close.
+func (b *Broadcaster[T]) Close() {
+	// wake up the publisher blocked on a slow subscriber first
+	b.closeOnce.Do(func() {
+		close(b.closing)
+	})
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	subs := b.subs
+	b.subs = nil
+	b.mu.Unlock()
+
+	for s := range subs {
+		s.close()
+	}
+}
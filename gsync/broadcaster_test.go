@@ -0,0 +1,136 @@
+package gsync_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/dashjay/gog/gsync"
+	"github.com/stretchr/testify/assert"
+)
+
+func drain[T any](ch <-chan T) (out []T) {
+	for v := range ch {
+		out = append(out, v)
+	}
+	return
+}
+
+func TestBroadcaster(t *testing.T) {
+	t.Parallel()
+
+	t.Run("overflow policies", func(t *testing.T) {
+		b := gsync.NewBroadcaster[int](0)
+		dropSub := b.Subscribe(2, gsync.OverflowDrop)
+		latestSub := b.Subscribe(2, gsync.OverflowLatest)
+		blockSub := b.Subscribe(0, gsync.OverflowBlock)
+		assert.Equal(t, 3, b.Len())
+
+		var got []int
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got = drain(blockSub.C)
+		}()
+		for i := 1; i <= 5; i++ {
+			assert.True(t, b.Publish(i))
+		}
+		b.Close()
+		wg.Wait()
+		assert.False(t, b.Publish(6))
+
+		assert.Equal(t, []int{1, 2}, drain(dropSub.C))
+		assert.Equal(t, uint64(3), dropSub.Dropped())
+		assert.Equal(t, []int{4, 5}, drain(latestSub.C))
+		assert.Equal(t, uint64(3), latestSub.Dropped())
+		assert.Equal(t, []int{1, 2, 3, 4, 5}, got)
+		assert.Equal(t, uint64(0), blockSub.Dropped())
+		assert.Equal(t, 0, b.Len())
+
+		// unsubscribe after close is safe
+		dropSub.Unsubscribe()
+		b.Close()
+	})
+
+	t.Run("unsubscribe releases blocked publisher", func(t *testing.T) {
+		b := gsync.NewBroadcaster[int](0)
+		blockSub := b.Subscribe(0, gsync.OverflowBlock)
+		other := b.Subscribe(1, gsync.OverflowDrop)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			b.Publish(1)
+		}()
+		blockSub.Unsubscribe()
+		<-done
+		blockSub.Unsubscribe()
+		assert.Equal(t, 1, b.Len())
+		_, ok := <-blockSub.C
+		assert.False(t, ok)
+		assert.Equal(t, 1, <-other.C)
+
+		// close releases blocked publisher too
+		blockSub = b.Subscribe(0, gsync.OverflowBlock)
+		done = make(chan struct{})
+		go func() {
+			defer close(done)
+			b.Publish(2)
+		}()
+		b.Close()
+		<-done
+	})
+
+	t.Run("callback uses broadcaster while publisher is blocked", func(t *testing.T) {
+		b := gsync.NewBroadcaster[int](0)
+		other := b.Subscribe(1, gsync.OverflowDrop)
+		var lens []int
+		done := make(chan struct{})
+		// the publisher blocks on the callback while it calls the broadcaster
+		b.SubscribeFunc(0, gsync.OverflowBlock, func(v int) {
+			lens = append(lens, b.Len())
+			if v == 3 {
+				other.Unsubscribe()
+				b.Subscribe(1, gsync.OverflowDrop)
+				close(done)
+			}
+		})
+		go func() {
+			for i := 1; i <= 3; i++ {
+				b.Publish(i)
+			}
+		}()
+		<-done
+		assert.Equal(t, []int{2, 2, 2}, lens)
+		assert.Equal(t, 2, b.Len())
+		b.Close()
+	})
+
+	t.Run("replay and callback", func(t *testing.T) {
+		b := gsync.NewBroadcaster[int](3)
+		for i := 1; i <= 5; i++ {
+			b.Publish(i)
+		}
+		s := b.Subscribe(0, gsync.OverflowBlock)
+		assert.Equal(t, []int{3, 4, 5}, []int{<-s.C, <-s.C, <-s.C})
+
+		var mu sync.Mutex
+		var got []int
+		done := make(chan struct{})
+		b.SubscribeFunc(10, gsync.OverflowDrop, func(v int) {
+			mu.Lock()
+			got = append(got, v)
+			if len(got) == 4 {
+				close(done)
+			}
+			mu.Unlock()
+		})
+		s.Unsubscribe()
+		b.Publish(6)
+		<-done
+		assert.Equal(t, []int{3, 4, 5, 6}, got)
+
+		b.Close()
+		s = b.Subscribe(1, gsync.OverflowDrop)
+		assert.Equal(t, []int{4, 5, 6}, drain(s.C))
+	})
+}
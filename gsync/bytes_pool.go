@@ -0,0 +1,116 @@
+package gsync
+
+import (
+	"bytes"
+	"math/bits"
+)
+
+// BytesPool is a pool of byte slices with power-of-two size classes,
+// each class is backed by a SyncPool, slices larger than the max size class are never retained.
+type BytesPool struct {
+	minShift int
+	maxShift int
+	// the classes hold *[]byte, since putting a []byte into an interface allocates,
+	// the emptied pointers are kept in headers to be reused by Put.
+	pools   []*SyncPool[*[]byte]
+	headers *SyncPool[*[]byte]
+}
+
+// NewBytesPool creates a new BytesPool with size classes from minSize to maxSize,
+// both are rounded up to a power of two.
+func NewBytesPool(minSize, maxSize int) *BytesPool {
+	if minSize <= 0 {
+		minSize = 1
+	}
+	if maxSize < minSize {
+		maxSize = minSize
+	}
+	p := &BytesPool{
+		minShift: ceilShift(minSize),
+		maxShift: ceilShift(maxSize),
+		headers: NewSyncPool(func() *[]byte {
+			return new([]byte)
+		}),
+	}
+	for shift := p.minShift; shift <= p.maxShift; shift++ {
+		size := 1 << shift
+		p.pools = append(p.pools, NewSyncPool(func() *[]byte {
+			b := make([]byte, 0, size)
+			return &b
+		}))
+	}
+	return p
+}
+
+// ceilShift returns the smallest shift that 1<<shift >= size.
+func ceilShift(size int) int {
+	if size <= 1 {
+		return 0
+	}
+	return bits.Len(uint(size - 1))
+}
+
+// Get returns a byte slice with length size from the smallest size class that fits,
+// the slice is allocated directly if size is larger than the max size class.
+func (p *BytesPool) Get(size int) []byte {
+	shift := ceilShift(size)
+	if shift > p.maxShift {
+		return make([]byte, size)
+	}
+	if shift < p.minShift {
+		shift = p.minShift
+	}
+	ptr := p.pools[shift-p.minShift].Get()
+	b := (*ptr)[:size]
+	*ptr = nil
+	p.headers.Put(ptr)
+	return b
+}
+
+// Put puts b back to the size class its capacity fits in,
+// b is dropped if its capacity is smaller than the min size class or larger than the max size class.
+func (p *BytesPool) Put(b []byte) {
+	c := cap(b)
+	if c == 0 || c > 1<<p.maxShift {
+		return
+	}
+	// floor, so that any slice got from this class has enough capacity
+	shift := bits.Len(uint(c)) - 1
+	if shift < p.minShift {
+		return
+	}
+	ptr := p.headers.Get()
+	*ptr = b[:0]
+	p.pools[shift-p.minShift].Put(ptr)
+}
+
+// BufferPool is a pool of *bytes.Buffer, buffers are reset on Put
+// and buffers grown larger than maxCap are not retained, so they do not pin memory.
+type BufferPool struct {
+	maxCap int
+	pool   *SyncPool[*bytes.Buffer]
+}
+
+// NewBufferPool creates a new BufferPool, maxCap <= 0 means no limit.
+func NewBufferPool(maxCap int) *BufferPool {
+	p := NewSyncPool(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	})
+	p.Reset = func(b *bytes.Buffer) {
+		b.Reset()
+	}
+	return &BufferPool{maxCap: maxCap, pool: p}
+}
+
+// Get returns an empty buffer.
+func (p *BufferPool) Get() *bytes.Buffer {
+	return p.pool.Get()
+}
+
+// Put resets b and puts it back, b is dropped if its capacity is larger than maxCap.
+func (p *BufferPool) Put(b *bytes.Buffer) {
+	if b == nil || (p.maxCap > 0 && b.Cap() > p.maxCap) {
+		return
+	}
+	p.pool.Put(b)
+}
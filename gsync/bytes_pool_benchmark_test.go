@@ -0,0 +1,51 @@
+package gsync_test
+
+import (
+	"testing"
+
+	"github.com/dashjay/gog/gsync"
+)
+
+func BenchmarkBytesPool(b *testing.B) {
+	sizes := []int{100, 1000, 4000, 16000, 60000}
+
+	b.Run("make", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				buf := make([]byte, sizes[i%len(sizes)])
+				buf[0] = 1
+				i++
+			}
+		})
+	})
+
+	b.Run("SyncPool", func(b *testing.B) {
+		const maxSize = 64 << 10
+		p := gsync.NewSyncPool(func() []byte {
+			return make([]byte, maxSize)
+		})
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				buf := p.Get()[:sizes[i%len(sizes)]]
+				buf[0] = 1
+				p.Put(buf[:maxSize])
+				i++
+			}
+		})
+	})
+
+	b.Run("BytesPool", func(b *testing.B) {
+		p := gsync.NewBytesPool(64, 64<<10)
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				buf := p.Get(sizes[i%len(sizes)])
+				buf[0] = 1
+				p.Put(buf)
+				i++
+			}
+		})
+	})
+}
@@ -0,0 +1,80 @@
+package gsync_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dashjay/gog/gsync"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBytesPool(t *testing.T) {
+	t.Parallel()
+
+	p := gsync.NewBytesPool(100, 4000)
+
+	b := p.Get(0)
+	assert.Len(t, b, 0)
+	assert.Equal(t, 128, cap(b))
+
+	b = p.Get(10)
+	assert.Len(t, b, 10)
+	assert.Equal(t, 128, cap(b))
+	p.Put(b)
+
+	b = p.Get(129)
+	assert.Len(t, b, 129)
+	assert.Equal(t, 256, cap(b))
+
+	b = p.Get(4096)
+	assert.Len(t, b, 4096)
+	assert.Equal(t, 4096, cap(b))
+	p.Put(b)
+
+	// oversize
+	b = p.Get(4097)
+	assert.Len(t, b, 4097)
+	p.Put(b)
+	p.Put(make([]byte, 0, 1<<20))
+	p.Put(make([]byte, 1))
+	p.Put(nil)
+
+	// a slice with odd capacity is put into the class it fills
+	p.Put(make([]byte, 300))
+	for i := 0; i < 100; i++ {
+		b = p.Get(256)
+		assert.Len(t, b, 256)
+		assert.GreaterOrEqual(t, cap(b), 256)
+		p.Put(b)
+	}
+
+	assert.Equal(t, 1, cap(gsync.NewBytesPool(0, -1).Get(1)))
+
+	// a slice larger than the max size class is not retained even if it is smaller than twice of it
+	p = gsync.NewBytesPool(64, 1024)
+	p.Put(make([]byte, 0, 2000))
+	for i := 0; i < 100; i++ {
+		b = p.Get(1000)
+		assert.Equal(t, 1024, cap(b))
+		p.Put(b)
+	}
+}
+
+func TestBufferPool(t *testing.T) {
+	t.Parallel()
+
+	p := gsync.NewBufferPool(1024)
+	for i := 0; i < 100; i++ {
+		b := p.Get()
+		assert.Equal(t, 0, b.Len())
+		b.WriteString("secret")
+		p.Put(b)
+	}
+
+	b := p.Get()
+	b.Write(make([]byte, 2048))
+	p.Put(b)
+	p.Put(nil)
+	p.Put(bytes.NewBuffer(make([]byte, 0, 512)))
+	assert.Equal(t, 0, p.Get().Len())
+}
@@ -0,0 +1,46 @@
+package gsync_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dashjay/gog/gsync"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManualClock(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(0, 0)
+	clock := gsync.NewManualClock(start)
+	assert.Equal(t, start, clock.Now())
+
+	t1 := clock.NewTimer(time.Second)
+	t2 := clock.NewTimer(2 * time.Second)
+	t3 := clock.NewTimer(3 * time.Second)
+	assert.Equal(t, 3, clock.Waiters())
+
+	assert.True(t, t3.Stop())
+	assert.False(t, t3.Stop())
+
+	clock.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Second), <-t1.C())
+	assert.False(t, t1.Stop())
+	select {
+	case <-t2.C():
+		t.Fatal("timer fired too early")
+	default:
+	}
+
+	clock.Set(start.Add(time.Hour))
+	assert.Equal(t, start.Add(time.Hour), <-t2.C())
+	assert.Equal(t, 0, clock.Waiters())
+
+	t4 := clock.NewTimer(0)
+	assert.Equal(t, start.Add(time.Hour), <-t4.C())
+
+	rt := gsync.RealClock.NewTimer(time.Millisecond)
+	<-rt.C()
+	assert.False(t, rt.Stop())
+	assert.False(t, gsync.RealClock.Now().IsZero())
+}
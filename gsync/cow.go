@@ -0,0 +1,174 @@
+package gsync
+
+import "sync"
+
+// COW is a copy-on-write container for read-mostly shared state.
+// Readers get an immutable snapshot with a single atomic load and never block,
+// writers are serialized by a mutex and publish a new snapshot.
+//
+// Gentleman's agreement: the snapshot returned by Load is shared by all readers, it must not be modified,
+// Update should build a new value instead of modifying the old one in place.
+type COW[T any] struct {
+	mu sync.Mutex
+	p  AtomicPointer[T]
+}
+
+// NewCOW returns a new COW holding v.
+func NewCOW[T any](v T) *COW[T] {
+	c := &COW[T]{}
+	c.p.Store(&v)
+	return c
+}
+
+// Load returns the current snapshot, the zero value of T if nothing has been stored.
+func (c *COW[T]) Load() (v T) {
+	if p := c.p.Load(); p != nil {
+		v = *p
+	}
+	return
+}
+
+// Store publishes v as the new snapshot.
+func (c *COW[T]) Store(v T) {
+	c.mu.Lock()
+	c.p.Store(&v)
+	c.mu.Unlock()
+}
+
+// Update calls f with the current snapshot and publishes the result of f as the new snapshot,
+// updates are serialized so no update is lost.
+func (c *COW[T]) Update(f func(old T) T) (new T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	new = f(c.Load())
+	c.p.Store(&new)
+	return new
+}
+
+// COWSlice is a copy-on-write slice, every modification copies the whole slice.
+type COWSlice[T any] struct {
+	c COW[[]T]
+}
+
+// NewCOWSlice returns a new COWSlice holding a copy of vs.
+func NewCOWSlice[T any](vs ...T) *COWSlice[T] {
+	s := &COWSlice[T]{}
+	s.c.Store(append([]T(nil), vs...))
+	return s
+}
+
+// Load returns the current snapshot, it must not be modified.
+func (s *COWSlice[T]) Load() []T {
+	return s.c.Load()
+}
+
+// Len returns the length of the current snapshot.
+func (s *COWSlice[T]) Len() int {
+	return len(s.c.Load())
+}
+
+// Append appends vs to the slice.
+func (s *COWSlice[T]) Append(vs ...T) {
+	s.c.Update(func(old []T) []T {
+		out := make([]T, 0, len(old)+len(vs))
+		out = append(out, old...)
+		return append(out, vs...)
+	})
+}
+
+// Set sets the element at index i to v, it panics if i is out of range.
+func (s *COWSlice[T]) Set(i int, v T) {
+	s.c.Update(func(old []T) []T {
+		out := append([]T(nil), old...)
+		out[i] = v
+		return out
+	})
+}
+
+// DeleteFunc deletes all elements satisfying f.
+func (s *COWSlice[T]) DeleteFunc(f func(T) bool) {
+	s.c.Update(func(old []T) []T {
+		out := make([]T, 0, len(old))
+		for _, v := range old {
+			if !f(v) {
+				out = append(out, v)
+			}
+		}
+		return out
+	})
+}
+
+// Update calls f with a copy of the current snapshot, f may modify it in place,
+// the result of f is published as the new snapshot.
+func (s *COWSlice[T]) Update(f func([]T) []T) {
+	s.c.Update(func(old []T) []T {
+		return f(append([]T(nil), old...))
+	})
+}
+
+// COWMap is a copy-on-write map, every modification copies the whole map.
+type COWMap[K comparable, V any] struct {
+	c COW[map[K]V]
+}
+
+// NewCOWMap returns a new COWMap holding a copy of m.
+func NewCOWMap[K comparable, V any](m map[K]V) *COWMap[K, V] {
+	cm := &COWMap[K, V]{}
+	cm.c.Store(copyMap(m, 0))
+	return cm
+}
+
+func copyMap[K comparable, V any](m map[K]V, extra int) map[K]V {
+	out := make(map[K]V, len(m)+extra)
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// Load returns the value stored in the current snapshot for key.
+func (m *COWMap[K, V]) Load(key K) (value V, ok bool) {
+	value, ok = m.c.Load()[key]
+	return
+}
+
+// Snapshot returns the current snapshot, it must not be modified.
+func (m *COWMap[K, V]) Snapshot() map[K]V {
+	return m.c.Load()
+}
+
+// Len returns the number of elements in the current snapshot.
+func (m *COWMap[K, V]) Len() int {
+	return len(m.c.Load())
+}
+
+// Store sets the value for key.
+func (m *COWMap[K, V]) Store(key K, value V) {
+	m.c.Update(func(old map[K]V) map[K]V {
+		out := copyMap(old, 1)
+		out[key] = value
+		return out
+	})
+}
+
+// Delete deletes the value for key.
+func (m *COWMap[K, V]) Delete(key K) {
+	m.c.Update(func(old map[K]V) map[K]V {
+		if _, exists := old[key]; !exists {
+			return old
+		}
+		out := copyMap(old, 0)
+		delete(out, key)
+		return out
+	})
+}
+
+// Update calls f with a copy of the current snapshot, f may modify it in place,
+// the map is published as the new snapshot after f returns.
+func (m *COWMap[K, V]) Update(f func(map[K]V)) {
+	m.c.Update(func(old map[K]V) map[K]V {
+		out := copyMap(old, 0)
+		f(out)
+		return out
+	})
+}
@@ -0,0 +1,106 @@
+package gsync_test
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/dashjay/gog/gsync"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCOW(t *testing.T) {
+	t.Parallel()
+
+	t.Run("load store update", func(t *testing.T) {
+		var zero gsync.COW[int]
+		assert.Equal(t, 0, zero.Load())
+
+		c := gsync.NewCOW(1)
+		assert.Equal(t, 1, c.Load())
+		c.Store(2)
+		assert.Equal(t, 3, c.Update(func(old int) int {
+			return old + 1
+		}))
+		assert.Equal(t, 3, c.Load())
+	})
+
+	t.Run("concurrent update", func(t *testing.T) {
+		c := gsync.NewCOW(0)
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					c.Update(func(old int) int {
+						return old + 1
+					})
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					_ = c.Load()
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 1000, c.Load())
+	})
+
+	t.Run("slice", func(t *testing.T) {
+		in := []int{1, 2}
+		s := gsync.NewCOWSlice(in...)
+		in[0] = 100
+		assert.Equal(t, []int{1, 2}, s.Load())
+
+		snapshot := s.Load()
+		s.Append(3, 4)
+		s.Set(0, 0)
+		assert.Equal(t, []int{1, 2}, snapshot)
+		assert.Equal(t, []int{0, 2, 3, 4}, s.Load())
+		assert.Equal(t, 4, s.Len())
+
+		s.DeleteFunc(func(i int) bool {
+			return i%2 == 0
+		})
+		assert.Equal(t, []int{3}, s.Load())
+
+		snapshot = s.Load()
+		s.Update(func(vs []int) []int {
+			vs[0] = 5
+			return append(vs, 6)
+		})
+		assert.Equal(t, []int{3}, snapshot)
+		assert.Equal(t, []int{5, 6}, s.Load())
+	})
+
+	t.Run("map", func(t *testing.T) {
+		m := gsync.NewCOWMap(map[string]int{"a": 1})
+		snapshot := m.Snapshot()
+		m.Store("b", 2)
+		m.Delete("a")
+		m.Delete("c")
+		assert.Equal(t, map[string]int{"a": 1}, snapshot)
+		assert.Equal(t, map[string]int{"b": 2}, m.Snapshot())
+		v, ok := m.Load("b")
+		assert.True(t, ok)
+		assert.Equal(t, 2, v)
+		_, ok = m.Load("a")
+		assert.False(t, ok)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				m.Update(func(m map[string]int) {
+					m[strconv.Itoa(i)] = i
+				})
+			}(i)
+		}
+		wg.Wait()
+		assert.Equal(t, 11, m.Len())
+	})
+}
@@ -0,0 +1,340 @@
+package gsync
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EvictReason is the reason why an entry is removed from an ExpiringMap.
+type EvictReason int
+
+const (
+	// EvictExpired means the entry expired.
+	EvictExpired EvictReason = iota
+	// EvictDeleted means the entry was deleted explicitly.
+	EvictDeleted
+)
+
+// ExpiringMapConfig configures an ExpiringMap, the zero value is a valid config without expiry by default.
+type ExpiringMapConfig[K comparable, V any] struct {
+	// ShardCount and Hasher are used like in NewShardedMap.
+	ShardCount int
+	Hasher     Hasher[K]
+	// DefaultTTL is the ttl used by Store, DefaultTTL <= 0 means no expiry.
+	DefaultTTL time.Duration
+	// Sliding extends the expiry of an entry by its ttl each time it is loaded.
+	Sliding bool
+	// JanitorInterval is the interval of the background eviction,
+	// JanitorInterval <= 0 means expired entries are only evicted lazily when accessed or by Evict.
+	JanitorInterval time.Duration
+	// OnEvict is called without holding any lock after an entry is removed.
+	OnEvict func(key K, value V, reason EvictReason)
+	// Clock is the time source, RealClock is used if nil.
+	Clock Clock
+}
+
+type expiringEntry[V any] struct {
+	value V
+	ttl   time.Duration
+	// expireAt is the unix nano of expiry, 0 means never, accessed atomically for sliding expiry.
+	expireAt int64
+}
+
+func (e *expiringEntry[V]) expired(now int64) bool {
+	expireAt := atomic.LoadInt64(&e.expireAt)
+	return expireAt != 0 && now >= expireAt
+}
+
+type computeResult[V any] struct {
+	value  V
+	loaded bool
+}
+
+// ExpiringMap is a concurrent map whose entries expire after a ttl, it is sharded like ShardedMap.
+type ExpiringMap[K comparable, V any] struct {
+	length int64
+	cfg    ExpiringMapConfig[K, V]
+	shards []mapShard[K, *expiringEntry[V]]
+	mask   uint64
+	// computes deduplicates the concurrent calls of LoadOrCompute for the same key.
+	computes SingleFlight[K, computeResult[V]]
+
+	stop      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewExpiringMap creates a new ExpiringMap, the background janitor is started if cfg.JanitorInterval > 0,
+// Close must be called to stop it.
+func NewExpiringMap[K comparable, V any](cfg ExpiringMapConfig[K, V]) *ExpiringMap[K, V] {
+	if cfg.Clock == nil {
+		cfg.Clock = RealClock
+	}
+	if cfg.ShardCount <= 0 {
+		cfg.ShardCount = DefaultShardCount
+	}
+	if cfg.Hasher == nil {
+		cfg.Hasher = defaultHasher[K]
+	}
+	n := 1
+	for n < cfg.ShardCount {
+		n <<= 1
+	}
+	m := &ExpiringMap[K, V]{
+		cfg:    cfg,
+		shards: make([]mapShard[K, *expiringEntry[V]], n),
+		mask:   uint64(n - 1),
+		stop:   make(chan struct{}),
+	}
+	for i := range m.shards {
+		m.shards[i].m = make(map[K]*expiringEntry[V])
+	}
+	if cfg.JanitorInterval > 0 {
+		m.wg.Add(1)
+		go m.janitor()
+	}
+	return m
+}
+
+func (m *ExpiringMap[K, V]) shard(key K) *mapShard[K, *expiringEntry[V]] {
+	return &m.shards[m.cfg.Hasher(key)&m.mask]
+}
+
+func (m *ExpiringMap[K, V]) now() int64 {
+	return m.cfg.Clock.Now().UnixNano()
+}
+
+func (m *ExpiringMap[K, V]) newEntry(value V, ttl time.Duration, now int64) *expiringEntry[V] {
+	e := &expiringEntry[V]{value: value, ttl: ttl}
+	if ttl > 0 {
+		e.expireAt = now + int64(ttl)
+	}
+	return e
+}
+
+func (m *ExpiringMap[K, V]) touch(e *expiringEntry[V], now int64) {
+	if m.cfg.Sliding && e.ttl > 0 {
+		atomic.StoreInt64(&e.expireAt, now+int64(e.ttl))
+	}
+}
+
+func (m *ExpiringMap[K, V]) onEvict(key K, value V, reason EvictReason) {
+	if m.cfg.OnEvict != nil {
+		m.cfg.OnEvict(key, value, reason)
+	}
+}
+
+// removeExpired removes e from sh if it is still the entry of key and expired.
+func (m *ExpiringMap[K, V]) removeExpired(sh *mapShard[K, *expiringEntry[V]], key K, e *expiringEntry[V], now int64) {
+	sh.mu.Lock()
+	removed := false
+	if cur, ok := sh.m[key]; ok && cur == e && e.expired(now) {
+		delete(sh.m, key)
+		atomic.AddInt64(&m.length, -1)
+		removed = true
+	}
+	sh.mu.Unlock()
+	if removed {
+		m.onEvict(key, e.value, EvictExpired)
+	}
+}
+
+// Load returns the value stored for key if it has not expired, the expiry is extended if Sliding is set.
+func (m *ExpiringMap[K, V]) Load(key K) (value V, ok bool) {
+	sh := m.shard(key)
+	sh.mu.RLock()
+	e, ok := sh.m[key]
+	sh.mu.RUnlock()
+	if !ok {
+		return
+	}
+	now := m.now()
+	if e.expired(now) {
+		m.removeExpired(sh, key, e, now)
+		return value, false
+	}
+	m.touch(e, now)
+	return e.value, true
+}
+
+// Store sets the value for key with DefaultTTL.
+func (m *ExpiringMap[K, V]) Store(key K, value V) {
+	m.StoreWithTTL(key, value, m.cfg.DefaultTTL)
+}
+
+// StoreWithTTL sets the value for key which expires after ttl, ttl <= 0 means never.
+func (m *ExpiringMap[K, V]) StoreWithTTL(key K, value V, ttl time.Duration) {
+	sh := m.shard(key)
+	e := m.newEntry(value, ttl, m.now())
+	sh.mu.Lock()
+	if _, exists := sh.m[key]; !exists {
+		atomic.AddInt64(&m.length, 1)
+	}
+	sh.m[key] = e
+	sh.mu.Unlock()
+}
+
+// LoadOrCompute returns the existing value for key if present and not expired.
+// Otherwise, it calls compute and stores its result with ttl unless compute returns an error.
+// The loaded result is true if the value was loaded, false if computed.
+//
+// compute is called without holding any lock, so a slow compute does not block other keys.
+// Concurrent callers of the same key wait for the same call of compute like SingleFlight.Do,
+// a panic in compute is propagated to all of them as a *PanicError.
+// compute may access the map, but must not call LoadOrCompute with the same key.
+func (m *ExpiringMap[K, V]) LoadOrCompute(key K, ttl time.Duration, compute func() (V, error)) (value V, loaded bool, err error) {
+	if value, loaded = m.Load(key); loaded {
+		return
+	}
+	leader := false
+	r, err, _ := m.computes.Do(key, func() (computeResult[V], error) {
+		leader = true
+		// the value may have been stored since Load
+		if v, ok := m.Load(key); ok {
+			return computeResult[V]{value: v, loaded: true}, nil
+		}
+		v, err := compute()
+		if err != nil {
+			return computeResult[V]{}, err
+		}
+		return m.storeIfAbsent(key, v, ttl), nil
+	})
+	if err != nil {
+		return value, false, err
+	}
+	// the callers waiting for the leader load the value it computed
+	return r.value, r.loaded || !leader, nil
+}
+
+// storeIfAbsent stores value for key with ttl unless a value which has not expired is present,
+// it returns the present value as loaded if there is one.
+func (m *ExpiringMap[K, V]) storeIfAbsent(key K, value V, ttl time.Duration) computeResult[V] {
+	sh := m.shard(key)
+	now := m.now()
+	sh.mu.Lock()
+	e, ok := sh.m[key]
+	if ok && !e.expired(now) {
+		sh.mu.Unlock()
+		m.touch(e, now)
+		return computeResult[V]{value: e.value, loaded: true}
+	}
+	if !ok {
+		atomic.AddInt64(&m.length, 1)
+	}
+	sh.m[key] = m.newEntry(value, ttl, now)
+	sh.mu.Unlock()
+	if ok {
+		m.onEvict(key, e.value, EvictExpired)
+	}
+	return computeResult[V]{value: value}
+}
+
+// LoadAndDelete deletes the value for key, returning the previous value if it has not expired.
+func (m *ExpiringMap[K, V]) LoadAndDelete(key K) (value V, loaded bool) {
+	sh := m.shard(key)
+	sh.mu.Lock()
+	e, ok := sh.m[key]
+	if ok {
+		delete(sh.m, key)
+		atomic.AddInt64(&m.length, -1)
+	}
+	sh.mu.Unlock()
+	if !ok {
+		return
+	}
+	if e.expired(m.now()) {
+		m.onEvict(key, e.value, EvictExpired)
+		return
+	}
+	m.onEvict(key, e.value, EvictDeleted)
+	return e.value, true
+}
+
+// Delete deletes the value for key.
+func (m *ExpiringMap[K, V]) Delete(key K) {
+	m.LoadAndDelete(key)
+}
+
+// Range calls f sequentially for each key and value which has not expired, it does not extend the expiry.
+// If f returns false, range stops the iteration. See ShardedMap.Range for the consistency guarantee.
+func (m *ExpiringMap[K, V]) Range(f func(key K, value V) bool) {
+	var buf []mapEntry[K, V]
+	for i := range m.shards {
+		sh := &m.shards[i]
+		now := m.now()
+		sh.mu.RLock()
+		buf = buf[:0]
+		for k, e := range sh.m {
+			if !e.expired(now) {
+				buf = append(buf, mapEntry[K, V]{key: k, value: e.value})
+			}
+		}
+		sh.mu.RUnlock()
+		for j := range buf {
+			if !f(buf[j].key, buf[j].value) {
+				return
+			}
+		}
+	}
+}
+
+// ToMap returns a copy of the entries which have not expired as a regular map.
+func (m *ExpiringMap[K, V]) ToMap() map[K]V {
+	out := make(map[K]V, m.Len())
+	m.Range(func(key K, value V) bool {
+		out[key] = value
+		return true
+	})
+	return out
+}
+
+// Len returns the number of entries in the map, including expired entries which have not been evicted yet.
+// The complexity is O(1).
+func (m *ExpiringMap[K, V]) Len() int {
+	return int(atomic.LoadInt64(&m.length))
+}
+
+// Evict removes all expired entries now and returns the number of removed entries.
+func (m *ExpiringMap[K, V]) Evict() int {
+	var evicted []mapEntry[K, V]
+	for i := range m.shards {
+		sh := &m.shards[i]
+		now := m.now()
+		sh.mu.Lock()
+		for k, e := range sh.m {
+			if e.expired(now) {
+				delete(sh.m, k)
+				atomic.AddInt64(&m.length, -1)
+				evicted = append(evicted, mapEntry[K, V]{key: k, value: e.value})
+			}
+		}
+		sh.mu.Unlock()
+	}
+	for _, kv := range evicted {
+		m.onEvict(kv.key, kv.value, EvictExpired)
+	}
+	return len(evicted)
+}
+
+func (m *ExpiringMap[K, V]) janitor() {
+	defer m.wg.Done()
+	for {
+		timer := m.cfg.Clock.NewTimer(m.cfg.JanitorInterval)
+		select {
+		case <-m.stop:
+			timer.Stop()
+			return
+		case <-timer.C():
+			m.Evict()
+		}
+	}
+}
+
+// Close stops the background janitor and waits for it to exit, the map is still usable after Close.
+func (m *ExpiringMap[K, V]) Close() {
+	m.closeOnce.Do(func() {
+		close(m.stop)
+	})
+	m.wg.Wait()
+}
@@ -0,0 +1,257 @@
+package gsync_test
+
+import (
+	"errors"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dashjay/gog/gsync"
+	"github.com/stretchr/testify/assert"
+)
+
+type evictRecord struct {
+	key    string
+	value  int
+	reason gsync.EvictReason
+}
+
+type evictRecorder struct {
+	mu      sync.Mutex
+	records []evictRecord
+}
+
+func (r *evictRecorder) onEvict(key string, value int, reason gsync.EvictReason) {
+	r.mu.Lock()
+	r.records = append(r.records, evictRecord{key: key, value: value, reason: reason})
+	r.mu.Unlock()
+}
+
+func (r *evictRecorder) get() []evictRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]evictRecord(nil), r.records...)
+}
+
+func TestExpiringMap(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ttl and lazy eviction", func(t *testing.T) {
+		clock := gsync.NewManualClock(time.Now())
+		var rec evictRecorder
+		m := gsync.NewExpiringMap(gsync.ExpiringMapConfig[string, int]{
+			DefaultTTL: time.Second,
+			OnEvict:    rec.onEvict,
+			Clock:      clock,
+		})
+		defer m.Close()
+
+		m.Store("a", 1)
+		m.StoreWithTTL("b", 2, 2*time.Second)
+		m.StoreWithTTL("c", 3, 0)
+		assert.Equal(t, 3, m.Len())
+
+		v, ok := m.Load("a")
+		assert.True(t, ok)
+		assert.Equal(t, 1, v)
+
+		clock.Advance(time.Second)
+		_, ok = m.Load("a")
+		assert.False(t, ok)
+		assert.Equal(t, []evictRecord{{key: "a", value: 1, reason: gsync.EvictExpired}}, rec.get())
+		assert.Equal(t, 2, m.Len())
+
+		got := m.ToMap()
+		assert.Equal(t, map[string]int{"b": 2, "c": 3}, got)
+
+		clock.Advance(time.Hour)
+		assert.Equal(t, 1, m.Evict())
+		assert.Equal(t, 1, m.Len())
+		v, ok = m.Load("c")
+		assert.True(t, ok)
+		assert.Equal(t, 3, v)
+
+		m.Delete("c")
+		m.Delete("c")
+		assert.Equal(t, 0, m.Len())
+		assert.Equal(t, []evictRecord{
+			{key: "a", value: 1, reason: gsync.EvictExpired},
+			{key: "b", value: 2, reason: gsync.EvictExpired},
+			{key: "c", value: 3, reason: gsync.EvictDeleted},
+		}, rec.get())
+	})
+
+	t.Run("sliding expiry", func(t *testing.T) {
+		clock := gsync.NewManualClock(time.Now())
+		m := gsync.NewExpiringMap(gsync.ExpiringMapConfig[string, int]{
+			DefaultTTL: time.Second,
+			Sliding:    true,
+			Clock:      clock,
+		})
+		m.Store("a", 1)
+		for i := 0; i < 10; i++ {
+			clock.Advance(time.Second / 2)
+			_, ok := m.Load("a")
+			assert.True(t, ok)
+		}
+		clock.Advance(time.Second)
+		_, ok := m.Load("a")
+		assert.False(t, ok)
+	})
+
+	t.Run("load or compute", func(t *testing.T) {
+		clock := gsync.NewManualClock(time.Now())
+		var rec evictRecorder
+		m := gsync.NewExpiringMap(gsync.ExpiringMapConfig[string, int]{
+			OnEvict: rec.onEvict,
+			Clock:   clock,
+		})
+		calls := 0
+		compute := func() (int, error) {
+			calls++
+			return calls, nil
+		}
+		v, loaded, err := m.LoadOrCompute("a", time.Second, compute)
+		assert.Nil(t, err)
+		assert.False(t, loaded)
+		assert.Equal(t, 1, v)
+
+		v, loaded, _ = m.LoadOrCompute("a", time.Second, compute)
+		assert.True(t, loaded)
+		assert.Equal(t, 1, v)
+
+		clock.Advance(time.Second)
+		v, loaded, _ = m.LoadOrCompute("a", time.Second, compute)
+		assert.False(t, loaded)
+		assert.Equal(t, 2, v)
+		assert.Equal(t, 1, m.Len())
+		assert.Equal(t, []evictRecord{{key: "a", value: 1, reason: gsync.EvictExpired}}, rec.get())
+
+		someErr := errors.New("some error")
+		_, _, err = m.LoadOrCompute("b", time.Second, func() (int, error) {
+			return 0, someErr
+		})
+		assert.Equal(t, someErr, err)
+		_, ok := m.Load("b")
+		assert.False(t, ok)
+
+		// concurrent callers compute once
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				v, _, _ := m.LoadOrCompute("c", 0, compute)
+				assert.Equal(t, 3, v)
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("load or compute panics", func(t *testing.T) {
+		m := gsync.NewExpiringMap(gsync.ExpiringMapConfig[string, int]{ShardCount: 1})
+		func() {
+			defer func() {
+				pe, ok := recover().(*gsync.PanicError)
+				assert.True(t, ok)
+				assert.Equal(t, "boom", pe.Value)
+			}()
+			_, _, _ = m.LoadOrCompute("a", 0, func() (int, error) {
+				panic("boom")
+			})
+		}()
+		_, ok := m.Load("a")
+		assert.False(t, ok)
+
+		// the key can be computed again
+		v, loaded, err := m.LoadOrCompute("a", 0, func() (int, error) {
+			return 1, nil
+		})
+		assert.Nil(t, err)
+		assert.False(t, loaded)
+		assert.Equal(t, 1, v)
+	})
+
+	t.Run("load or compute does not block other keys", func(t *testing.T) {
+		m := gsync.NewExpiringMap(gsync.ExpiringMapConfig[string, int]{ShardCount: 1})
+		computing := make(chan struct{})
+		release := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			v, loaded, _ := m.LoadOrCompute("slow", 0, func() (int, error) {
+				close(computing)
+				<-release
+				return 1, nil
+			})
+			assert.False(t, loaded)
+			assert.Equal(t, 1, v)
+		}()
+		<-computing
+		// the only shard is not locked while computing
+		m.Store("b", 2)
+		v, ok := m.Load("b")
+		assert.True(t, ok)
+		assert.Equal(t, 2, v)
+		close(release)
+		<-done
+		v, ok = m.Load("slow")
+		assert.True(t, ok)
+		assert.Equal(t, 1, v)
+	})
+
+	t.Run("janitor", func(t *testing.T) {
+		clock := gsync.NewManualClock(time.Now())
+		var rec evictRecorder
+		m := gsync.NewExpiringMap(gsync.ExpiringMapConfig[string, int]{
+			ShardCount:      4,
+			DefaultTTL:      time.Second,
+			JanitorInterval: time.Minute,
+			OnEvict:         rec.onEvict,
+			Clock:           clock,
+		})
+		for i := 0; i < 100; i++ {
+			m.Store(strconv.Itoa(i), i)
+		}
+		for clock.Waiters() != 1 {
+			runtime.Gosched()
+		}
+		clock.Advance(time.Minute)
+		for m.Len() != 0 {
+			runtime.Gosched()
+		}
+		for len(rec.get()) != 100 {
+			runtime.Gosched()
+		}
+		m.Close()
+		m.Close()
+		assert.Equal(t, 0, clock.Waiters())
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		m := gsync.NewExpiringMap(gsync.ExpiringMapConfig[string, int]{
+			DefaultTTL:      time.Millisecond,
+			JanitorInterval: time.Millisecond,
+		})
+		defer m.Close()
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 1000; j++ {
+					key := strconv.Itoa(j % 10)
+					m.Store(key, j)
+					m.Load(key)
+					if j%3 == 0 {
+						m.Delete(key)
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+		assert.LessOrEqual(t, m.Len(), 10)
+	})
+}
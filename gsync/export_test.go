@@ -0,0 +1,8 @@
+package gsync
+
+// SemaphoreWaiters returns the number of waiters queued in s, it is exported for the tests of package gsync_test.
+func SemaphoreWaiters(s *Semaphore) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.waiters.Len()
+}
@@ -0,0 +1,208 @@
+package gsync
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrNoFutures is the error of AnyOf and Race when no future is given.
+var ErrNoFutures = errors.New("gsync: no futures given")
+
+// Future is a placeholder for a result which becomes available later,
+// it is settled once by Resolve or Reject, later calls have no effect.
+// The zero value is a Future which is not settled yet.
+type Future[T any] struct {
+	initOnce sync.Once
+	done     chan struct{}
+	once     sync.Once
+	val      T
+	err      error
+}
+
+// NewFuture returns a new Future which is not settled yet.
+func NewFuture[T any]() *Future[T] {
+	return newFuture[T]()
+}
+
+func newFuture[T any]() *Future[T] {
+	return &Future[T]{done: make(chan struct{})}
+}
+
+// doneChan returns the channel closed on completion, creating it for the zero value.
+func (f *Future[T]) doneChan() chan struct{} {
+	f.initOnce.Do(func() {
+		if f.done == nil {
+			f.done = make(chan struct{})
+		}
+	})
+	return f.done
+}
+
+// Async calls fn in a new goroutine and returns a Future of its result, a panic in fn is recovered as a *PanicError.
+func Async[T any](fn func() (T, error)) *Future[T] {
+	f := newFuture[T]()
+	go func() {
+		var val T
+		var err error
+		defer func() {
+			if r := recover(); r != nil {
+				err = newPanicError(r)
+			}
+			f.complete(val, err)
+		}()
+		val, err = fn()
+	}()
+	return f
+}
+
+// Resolve settles the future with val, it reports whether the future was not settled before.
+func (f *Future[T]) Resolve(val T) bool {
+	return f.complete(val, nil)
+}
+
+// Reject settles the future with err, it reports whether the future was not settled before.
+func (f *Future[T]) Reject(err error) bool {
+	var zero T
+	return f.complete(zero, err)
+}
+
+// complete sets the result of the future, only the first call takes effect, it reports whether it took effect.
+func (f *Future[T]) complete(val T, err error) (completed bool) {
+	f.once.Do(func() {
+		f.val, f.err = val, err
+		close(f.doneChan())
+		completed = true
+	})
+	return
+}
+
+// Done returns a channel which is closed when the result is available.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.doneChan()
+}
+
+// Await blocks until the result is available or ctx is done,
+// it returns ctx.Err() with zero value if ctx is done first.
+func (f *Future[T]) Await(ctx context.Context) (val T, err error) {
+	select {
+	case <-f.doneChan():
+		return f.val, f.err
+	default:
+	}
+	select {
+	case <-f.doneChan():
+		return f.val, f.err
+	case <-ctx.Done():
+		return val, ctx.Err()
+	}
+}
+
+// Get blocks until the result is available.
+func (f *Future[T]) Get() (T, error) {
+	<-f.doneChan()
+	return f.val, f.err
+}
+
+// Then returns a Future of fn applied to the value of f, fn is not called if f is rejected,
+// the returned future is rejected with the same error instead.
+func Then[T, R any](f *Future[T], fn func(T) (R, error)) *Future[R] {
+	return Async(func() (r R, err error) {
+		val, err := f.Get()
+		if err != nil {
+			return r, err
+		}
+		return fn(val)
+	})
+}
+
+// MapFuture is like Then for a fn which cannot fail.
+func MapFuture[T, R any](f *Future[T], fn func(T) R) *Future[R] {
+	return Then(f, func(val T) (R, error) {
+		return fn(val), nil
+	})
+}
+
+// AllOf returns a Future of the values of all futures in order,
+// it is rejected as soon as any of the futures is rejected.
+func AllOf[T any](futures ...*Future[T]) *Future[[]T] {
+	out := newFuture[[]T]()
+	values := make([]T, len(futures))
+	var wg sync.WaitGroup
+	for i, f := range futures {
+		wg.Add(1)
+		go func(i int, f *Future[T]) {
+			defer wg.Done()
+			select {
+			case <-f.doneChan():
+			case <-out.doneChan():
+				// already rejected by another future
+				return
+			}
+			if f.err != nil {
+				out.Reject(f.err)
+				return
+			}
+			values[i] = f.val
+		}(i, f)
+	}
+	go func() {
+		wg.Wait()
+		out.Resolve(values)
+	}()
+	return out
+}
+
+// AnyOf returns a Future of the value of the first resolved future,
+// if all futures are rejected, it is rejected with the error of the first future in argument order.
+func AnyOf[T any](futures ...*Future[T]) *Future[T] {
+	out := newFuture[T]()
+	if len(futures) == 0 {
+		out.Reject(ErrNoFutures)
+		return out
+	}
+	var mu sync.Mutex
+	errs := make([]error, len(futures))
+	remaining := len(futures)
+	for i, f := range futures {
+		go func(i int, f *Future[T]) {
+			select {
+			case <-f.doneChan():
+			case <-out.doneChan():
+				return
+			}
+			if f.err == nil {
+				out.Resolve(f.val)
+				return
+			}
+			mu.Lock()
+			errs[i] = f.err
+			remaining--
+			last := remaining == 0
+			mu.Unlock()
+			if last {
+				out.Reject(errs[0])
+			}
+		}(i, f)
+	}
+	return out
+}
+
+// Race returns a Future settled like the first settled future, either resolved or rejected.
+func Race[T any](futures ...*Future[T]) *Future[T] {
+	out := newFuture[T]()
+	if len(futures) == 0 {
+		out.Reject(ErrNoFutures)
+		return out
+	}
+	for _, f := range futures {
+		go func(f *Future[T]) {
+			select {
+			case <-f.doneChan():
+				out.complete(f.val, f.err)
+			case <-out.doneChan():
+			}
+		}(f)
+	}
+	return out
+}
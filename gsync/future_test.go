@@ -0,0 +1,171 @@
+package gsync
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuture(t *testing.T) {
+	t.Parallel()
+
+	f := newFuture[int]()
+	select {
+	case <-f.Done():
+		t.Fatal("future should not be done")
+	default:
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := f.Await(ctx)
+	assert.Equal(t, context.Canceled, err)
+
+	go func() {
+		assert.True(t, f.complete(1, nil))
+	}()
+	v, err := f.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, v)
+	assert.False(t, f.complete(2, errors.New("some error")))
+
+	// a done future is returned even if ctx is done
+	v, err = f.Await(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, v)
+}
+
+func TestFutureResolveReject(t *testing.T) {
+	t.Parallel()
+
+	f := NewFuture[int]()
+	assert.True(t, f.Resolve(1))
+	assert.False(t, f.Resolve(2))
+	assert.False(t, f.Reject(errors.New("some error")))
+	v, err := f.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, v)
+
+	someErr := errors.New("some error")
+	f = NewFuture[int]()
+	assert.True(t, f.Reject(someErr))
+	_, err = f.Await(context.Background())
+	assert.Equal(t, someErr, err)
+
+	v, err = Async(func() (int, error) {
+		return 3, nil
+	}).Get()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, v)
+
+	_, err = Async(func() (int, error) {
+		panic("boom")
+	}).Get()
+	var pe *PanicError
+	assert.True(t, errors.As(err, &pe))
+
+	// the zero value is ready to use
+	var zf Future[int]
+	go func() {
+		assert.True(t, zf.Resolve(4))
+	}()
+	<-zf.Done()
+	v, err = zf.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, 4, v)
+}
+
+func TestFutureChaining(t *testing.T) {
+	t.Parallel()
+
+	f := NewFuture[int]()
+	s := MapFuture(Then(f, func(v int) (int, error) {
+		return v * 2, nil
+	}), strconv.Itoa)
+	f.Resolve(21)
+	v, err := s.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, "42", v)
+
+	someErr := errors.New("some error")
+	called := false
+	f = NewFuture[int]()
+	s = MapFuture(f, func(v int) string {
+		called = true
+		return strconv.Itoa(v)
+	})
+	f.Reject(someErr)
+	_, err = s.Get()
+	assert.Equal(t, someErr, err)
+	assert.False(t, called)
+
+	_, err = Then(Async(func() (int, error) {
+		return 1, nil
+	}), func(int) (int, error) {
+		return 0, someErr
+	}).Get()
+	assert.Equal(t, someErr, err)
+}
+
+func TestFutureCombinators(t *testing.T) {
+	t.Parallel()
+
+	someErr := errors.New("some error")
+	resolved := func(v int) *Future[int] {
+		f := NewFuture[int]()
+		f.Resolve(v)
+		return f
+	}
+	rejected := func(err error) *Future[int] {
+		f := NewFuture[int]()
+		f.Reject(err)
+		return f
+	}
+
+	t.Run("all of", func(t *testing.T) {
+		pending := NewFuture[int]()
+		all := AllOf(resolved(1), pending, resolved(3))
+		pending.Resolve(2)
+		v, err := all.Get()
+		assert.Nil(t, err)
+		assert.Equal(t, []int{1, 2, 3}, v)
+
+		// fail fast without waiting for the pending future
+		_, err = AllOf(NewFuture[int](), rejected(someErr)).Get()
+		assert.Equal(t, someErr, err)
+
+		v, err = AllOf[int]().Get()
+		assert.Nil(t, err)
+		assert.Len(t, v, 0)
+	})
+
+	t.Run("any of", func(t *testing.T) {
+		v, err := AnyOf(rejected(someErr), NewFuture[int](), resolved(2)).Get()
+		assert.Nil(t, err)
+		assert.Equal(t, 2, v)
+
+		f := NewFuture[int]()
+		any := AnyOf(f, rejected(someErr))
+		firstErr := errors.New("first error")
+		f.Reject(firstErr)
+		_, err = any.Get()
+		assert.Equal(t, firstErr, err)
+
+		_, err = AnyOf[int]().Get()
+		assert.Equal(t, ErrNoFutures, err)
+	})
+
+	t.Run("race", func(t *testing.T) {
+		v, err := Race(NewFuture[int](), resolved(1)).Get()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, v)
+
+		_, err = Race(NewFuture[int](), rejected(someErr)).Get()
+		assert.Equal(t, someErr, err)
+
+		_, err = Race[int]().Get()
+		assert.Equal(t, ErrNoFutures, err)
+	})
+}
@@ -0,0 +1,110 @@
+package gsync
+
+import (
+	"context"
+	"sync"
+)
+
+// Group is a collection of goroutines working on subtasks that are part of the same overall task,
+// it is the generic version of golang.org/x/sync/errgroup which also collects the results of subtasks.
+//
+// A zero Group is valid, has no limit on the number of active goroutines, and does not cancel on error.
+type Group[T any] struct {
+	cancel context.CancelFunc
+	sem    chan struct{}
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	results []T
+	err     error
+}
+
+// NewGroup returns a new Group and an associated Context derived from ctx.
+// The derived Context is canceled the first time a function passed to Go returns a non-nil error
+// or panics, or the first time Wait returns, whichever occurs first.
+//
+// limit is the maximum number of goroutines running at the same time, limit <= 0 means no limit.
+func NewGroup[T any](ctx context.Context, limit int) (*Group[T], context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	g := &Group[T]{cancel: cancel}
+	if limit > 0 {
+		g.sem = make(chan struct{}, limit)
+	}
+	return g, ctx
+}
+
+// Go calls f in a new goroutine, the result of f is stored at the position of this call in the results of Wait.
+// If the limit of goroutines is reached, Go blocks until a running goroutine returns.
+//
+// A panic in f is recovered and reported as a *PanicError.
+func (g *Group[T]) Go(f func() (T, error)) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.start(f)
+}
+
+// TryGo calls f in a new goroutine only if the limit of goroutines is not reached,
+// it reports whether f was started.
+func (g *Group[T]) TryGo(f func() (T, error)) bool {
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		default:
+			return false
+		}
+	}
+	g.start(f)
+	return true
+}
+
+func (g *Group[T]) start(f func() (T, error)) {
+	g.mu.Lock()
+	idx := len(g.results)
+	var zero T
+	g.results = append(g.results, zero)
+	g.mu.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.done()
+		v, err := g.call(f)
+		g.mu.Lock()
+		g.results[idx] = v
+		if err != nil && g.err == nil {
+			g.err = err
+			if g.cancel != nil {
+				g.cancel()
+			}
+		}
+		g.mu.Unlock()
+	}()
+}
+
+func (g *Group[T]) call(f func() (T, error)) (v T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = newPanicError(r)
+		}
+	}()
+	return f()
+}
+
+func (g *Group[T]) done() {
+	if g.sem != nil {
+		<-g.sem
+	}
+	g.wg.Done()
+}
+
+// Wait blocks until all function calls from the Go method have returned,
+// then returns their results in submission order and the first non-nil error (if any) from them.
+func (g *Group[T]) Wait() ([]T, error) {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel()
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.results, g.err
+}
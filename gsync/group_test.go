@@ -0,0 +1,125 @@
+package gsync_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dashjay/gog/gslice"
+	"github.com/dashjay/gog/gsync"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup(t *testing.T) {
+	t.Parallel()
+
+	t.Run("results in submission order", func(t *testing.T) {
+		g, _ := gsync.NewGroup[int](context.Background(), 0)
+		for i := 0; i < 100; i++ {
+			i := i
+			g.Go(func() (int, error) {
+				return i * i, nil
+			})
+		}
+		res, err := g.Wait()
+		assert.Nil(t, err)
+		assert.Equal(t, gslice.RepeatBy(100, func(i int) int { return i * i }), res)
+	})
+
+	t.Run("batch with chunk", func(t *testing.T) {
+		g, _ := gsync.NewGroup[int](context.Background(), 2)
+		for _, chunk := range gslice.Chunk(gslice.RepeatBy(10, func(i int) int { return i }), 3) {
+			chunk := chunk
+			g.Go(func() (int, error) {
+				sum := 0
+				for _, v := range chunk {
+					sum += v
+				}
+				return sum, nil
+			})
+		}
+		res, err := g.Wait()
+		assert.Nil(t, err)
+		assert.Equal(t, []int{3, 12, 21, 9}, res)
+	})
+
+	t.Run("first error cancels context", func(t *testing.T) {
+		someErr := errors.New("some error")
+		g, ctx := gsync.NewGroup[int](context.Background(), 0)
+		g.Go(func() (int, error) {
+			<-ctx.Done()
+			return 1, nil
+		})
+		g.Go(func() (int, error) {
+			return 0, someErr
+		})
+		res, err := g.Wait()
+		assert.Equal(t, someErr, err)
+		assert.Equal(t, []int{1, 0}, res)
+		assert.Equal(t, context.Canceled, ctx.Err())
+	})
+
+	t.Run("panic recovered", func(t *testing.T) {
+		g, ctx := gsync.NewGroup[string](context.Background(), 0)
+		g.Go(func() (string, error) {
+			panic("boom")
+		})
+		_, err := g.Wait()
+		var pe *gsync.PanicError
+		assert.True(t, errors.As(err, &pe))
+		assert.Equal(t, "boom", pe.Value)
+		assert.NotNil(t, ctx.Err())
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		const limit = 3
+		g, _ := gsync.NewGroup[int](context.Background(), limit)
+		var running, maxRunning int32
+		block := make(chan struct{})
+		for i := 0; i < limit; i++ {
+			g.Go(func() (int, error) {
+				n := atomic.AddInt32(&running, 1)
+				for {
+					m := atomic.LoadInt32(&maxRunning)
+					if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+						break
+					}
+				}
+				<-block
+				atomic.AddInt32(&running, -1)
+				return 0, nil
+			})
+		}
+		assert.False(t, g.TryGo(func() (int, error) { return 0, nil }))
+		close(block)
+		for i := 0; i < 10; i++ {
+			g.Go(func() (int, error) {
+				n := atomic.AddInt32(&running, 1)
+				assert.LessOrEqual(t, n, int32(limit))
+				atomic.AddInt32(&running, -1)
+				return 1, nil
+			})
+		}
+		res, err := g.Wait()
+		assert.Nil(t, err)
+		assert.Len(t, res, limit+10)
+		assert.LessOrEqual(t, atomic.LoadInt32(&maxRunning), int32(limit))
+
+		g2, _ := gsync.NewGroup[int](context.Background(), 0)
+		assert.True(t, g2.TryGo(func() (int, error) { return 1, nil }))
+		res, err = g2.Wait()
+		assert.Nil(t, err)
+		assert.Equal(t, []int{1}, res)
+	})
+
+	t.Run("zero value", func(t *testing.T) {
+		var g gsync.Group[int]
+		someErr := errors.New("some error")
+		g.Go(func() (int, error) { return 1, nil })
+		g.Go(func() (int, error) { return 0, someErr })
+		res, err := g.Wait()
+		assert.Equal(t, someErr, err)
+		assert.Equal(t, []int{1, 0}, res)
+	})
+}
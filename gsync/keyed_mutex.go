@@ -0,0 +1,93 @@
+package gsync
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+type keyedMutexEntry struct {
+	mu sync.Mutex
+	// refs is the number of goroutines holding or waiting for mu, -1 means the entry is being removed.
+	refs int32
+}
+
+// KeyedMutex is a set of mutexes identified by keys, e.g. serializing work per tenant.
+// Entries are created on demand and removed as soon as no goroutine holds or waits for them,
+// so the memory used is bounded by the number of keys in use.
+//
+// The zero value is ready to use.
+type KeyedMutex[K comparable] struct {
+	m SyncMap[K, *keyedMutexEntry]
+}
+
+// NewKeyedMutex returns a new KeyedMutex.
+func NewKeyedMutex[K comparable]() *KeyedMutex[K] {
+	return &KeyedMutex[K]{}
+}
+
+// acquire returns the entry of key with a reference held.
+func (k *KeyedMutex[K]) acquire(key K) *keyedMutexEntry {
+	for {
+		e, ok := k.m.Load(key)
+		if !ok {
+			e, _ = k.m.LoadOrStore(key, &keyedMutexEntry{})
+		}
+		for {
+			refs := atomic.LoadInt32(&e.refs)
+			if refs < 0 {
+				break
+			}
+			if atomic.CompareAndSwapInt32(&e.refs, refs, refs+1) {
+				return e
+			}
+		}
+		// the entry is being removed by its last holder, wait for it to disappear
+		runtime.Gosched()
+	}
+}
+
+// release drops the reference of e and removes e if it is not used anymore.
+func (k *KeyedMutex[K]) release(key K, e *keyedMutexEntry) {
+	if atomic.AddInt32(&e.refs, -1) == 0 && atomic.CompareAndSwapInt32(&e.refs, 0, -1) {
+		// nobody can get a reference of a removing entry, so the entry in the map is still e
+		k.m.Delete(key)
+	}
+}
+
+// Lock locks the mutex of key.
+func (k *KeyedMutex[K]) Lock(key K) {
+	k.acquire(key).mu.Lock()
+}
+
+// TryLock tries to lock the mutex of key and reports whether it succeeded.
+func (k *KeyedMutex[K]) TryLock(key K) bool {
+	e := k.acquire(key)
+	if e.mu.TryLock() {
+		return true
+	}
+	k.release(key, e)
+	return false
+}
+
+// Unlock unlocks the mutex of key, it panics if the mutex of key is not locked.
+func (k *KeyedMutex[K]) Unlock(key K) {
+	e, ok := k.m.Load(key)
+	if !ok {
+		panic("gsync: unlock of unlocked KeyedMutex key")
+	}
+	e.mu.Unlock()
+	k.release(key, e)
+}
+
+// LockCB is a shortcut for k.Lock(key) and defer k.Unlock(key).
+func (k *KeyedMutex[K]) LockCB(key K, cb func()) {
+	k.Lock(key)
+	defer k.Unlock(key)
+	cb()
+}
+
+// Len returns the number of keys locked or waited for.
+func (k *KeyedMutex[K]) Len() int {
+	return k.m.Len()
+}
@@ -0,0 +1,53 @@
+package gsync_test
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/dashjay/gog/gsync"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyedMutex(t *testing.T) {
+	t.Parallel()
+
+	t.Run("lock per key", func(t *testing.T) {
+		var k gsync.KeyedMutex[string]
+		k.Lock("a")
+		assert.False(t, k.TryLock("a"))
+		assert.True(t, k.TryLock("b"))
+		assert.Equal(t, 2, k.Len())
+		k.Unlock("a")
+		k.Unlock("b")
+		assert.Equal(t, 0, k.Len())
+
+		assert.Panics(t, func() {
+			k.Unlock("a")
+		})
+	})
+
+	t.Run("serialize per key and gc idle entries", func(t *testing.T) {
+		k := gsync.NewKeyedMutex[string]()
+		counters := make(map[string]*int)
+		for i := 0; i < 10; i++ {
+			counters[strconv.Itoa(i)] = new(int)
+		}
+		var wg sync.WaitGroup
+		for i := 0; i < 1000; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				key := strconv.Itoa(i % 10)
+				k.LockCB(key, func() {
+					*counters[key]++
+				})
+			}(i)
+		}
+		wg.Wait()
+		for _, c := range counters {
+			assert.Equal(t, 100, *c)
+		}
+		assert.Equal(t, 0, k.Len())
+	})
+}
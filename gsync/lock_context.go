@@ -0,0 +1,258 @@
+package gsync
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dashjay/gog/gstl"
+)
+
+type rwMutexWaiter struct {
+	read  bool
+	ready chan struct{}
+}
+
+// rwMutex is a reader/writer mutual exclusion lock whose waiters can give up.
+// Waiters are queued and served in FIFO order, so a waiting writer blocks the readers coming after it,
+// and a waiter which gives up leaves the queue at once, nothing keeps waiting for the lock on its behalf.
+//
+// The zero value is an unlocked mutex.
+type rwMutex struct {
+	// debug is first, so it adds no padding when it is empty
+	debug   lockDebugInfo
+	mu      sync.Mutex
+	writer  bool
+	readers int
+	waiters gstl.List[rwMutexWaiter]
+}
+
+// free reports whether the lock can be acquired in the given mode right now, m.mu must be held.
+func (m *rwMutex) free(read bool) bool {
+	if read {
+		return !m.writer
+	}
+	return !m.writer && m.readers == 0
+}
+
+// acquireLocked takes the lock in the given mode, m.mu must be held.
+func (m *rwMutex) acquireLocked(read bool) {
+	if read {
+		m.readers++
+	} else {
+		m.writer = true
+	}
+}
+
+// notifyWaiters hands the lock over to the waiters in FIFO order as long as it is free, m.mu must be held.
+func (m *rwMutex) notifyWaiters() {
+	for {
+		next := m.waiters.Front()
+		if next == nil {
+			return
+		}
+		w := next.Value
+		if !m.free(w.read) {
+			// stop here to keep FIFO order
+			return
+		}
+		m.acquireLocked(w.read)
+		m.waiters.Remove(next)
+		close(w.ready)
+	}
+}
+
+func (m *rwMutex) tryLock(read bool) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.waiters.Len() > 0 || !m.free(read) {
+		return false
+	}
+	m.acquireLocked(read)
+	return true
+}
+
+// lockUntil acquires the lock in the given mode, giving up when done or timeout is readable,
+// a nil channel never gives up. It reports whether the lock is acquired.
+func (m *rwMutex) lockUntil(read bool, done <-chan struct{}, timeout <-chan time.Time) bool {
+	m.mu.Lock()
+	if m.waiters.Len() == 0 && m.free(read) {
+		m.acquireLocked(read)
+		m.mu.Unlock()
+		return true
+	}
+	ready := make(chan struct{})
+	elem := m.waiters.PushBack(rwMutexWaiter{read: read, ready: ready})
+	m.mu.Unlock()
+
+	select {
+	case <-ready:
+		return true
+	case <-done:
+	case <-timeout:
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	select {
+	case <-ready:
+		// the lock was handed over while giving up, pretend it was not
+		m.releaseLocked(read)
+	default:
+		m.waiters.Remove(elem)
+		// a writer leaving the front of the queue may unblock the readers behind it
+		m.notifyWaiters()
+	}
+	return false
+}
+
+// releaseLocked releases the lock held in the given mode, m.mu must be held.
+func (m *rwMutex) releaseLocked(read bool) {
+	if read {
+		if m.readers == 0 {
+			panic("gsync: RUnlock of unlocked RWMutex")
+		}
+		m.readers--
+	} else {
+		if !m.writer {
+			panic("gsync: unlock of unlocked mutex")
+		}
+		m.writer = false
+	}
+	m.notifyWaiters()
+}
+
+func (m *rwMutex) unlock(read bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.releaseLocked(read)
+}
+
+func (m *rwMutex) Lock() {
+	m.lockUntil(false, nil, nil)
+}
+
+func (m *rwMutex) Unlock() {
+	m.unlock(false)
+}
+
+func (m *rwMutex) TryLock() bool {
+	return m.tryLock(false)
+}
+
+func (m *rwMutex) LockUntil(done <-chan struct{}, timeout <-chan time.Time) bool {
+	return m.lockUntil(false, done, timeout)
+}
+
+// rlocker adapts the read lock of a rwMutex to cancelableLocker.
+type rlocker rwMutex
+
+func (r *rlocker) Lock()         { (*rwMutex)(r).lockUntil(true, nil, nil) }
+func (r *rlocker) Unlock()       { (*rwMutex)(r).unlock(true) }
+func (r *rlocker) TryLock() bool { return (*rwMutex)(r).tryLock(true) }
+
+func (r *rlocker) LockUntil(done <-chan struct{}, timeout <-chan time.Time) bool {
+	return (*rwMutex)(r).lockUntil(true, done, timeout)
+}
+
+// cancelableLocker is a lock whose acquisition can give up.
+type cancelableLocker interface {
+	sync.Locker
+	TryLock() bool
+	// LockUntil acquires the lock, giving up when done or timeout is readable, it reports whether the lock is acquired.
+	LockUntil(done <-chan struct{}, timeout <-chan time.Time) bool
+}
+
+func lockContext(ctx context.Context, l cancelableLocker) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if l.LockUntil(ctx.Done(), nil) {
+		return nil
+	}
+	return ctx.Err()
+}
+
+func lockFor(clock Clock, d time.Duration, l cancelableLocker) bool {
+	if d <= 0 {
+		return l.TryLock()
+	}
+	timer := clock.NewTimer(d)
+	defer timer.Stop()
+	return l.LockUntil(nil, timer.C())
+}
+
+// LockContext locks and gets the value, it returns ctx.Err() with zero value if ctx is done before the lock is acquired.
+func (l *LockedValue[T]) LockContext(ctx context.Context) (val T, err error) {
+	debugBeforeLock(&l.mu, false)
+	if err = lockContext(ctx, l.locker()); err != nil {
+		return
+	}
+	debugAcquired(&l.mu, false)
+	return l.value, nil
+}
+
+// TryLockFor return true with value if lock successfully within d, return false with zero value if timeout.
+func (l *LockedValue[T]) TryLockFor(d time.Duration) (val T, locked bool) {
+	return l.tryLockFor(RealClock, d)
+}
+
+func (l *LockedValue[T]) tryLockFor(clock Clock, d time.Duration) (val T, locked bool) {
+	debugBeforeLock(&l.mu, false)
+	locked = lockFor(clock, d, l.locker())
+	if locked {
+		debugAcquired(&l.mu, false)
+		val = l.value
+	}
+	return
+}
+
+// LockContext locks and gets the value, it returns ctx.Err() with zero value if ctx is done before the lock is acquired.
+func (l *RWLockedValue[T]) LockContext(ctx context.Context) (val T, err error) {
+	debugBeforeLock(&l.mu, false)
+	if err = lockContext(ctx, l.locker()); err != nil {
+		return
+	}
+	debugAcquired(&l.mu, false)
+	return l.value, nil
+}
+
+// TryLockFor return true with value if lock successfully within d, return false with zero value if timeout.
+func (l *RWLockedValue[T]) TryLockFor(d time.Duration) (val T, locked bool) {
+	return l.tryLockFor(RealClock, d)
+}
+
+func (l *RWLockedValue[T]) tryLockFor(clock Clock, d time.Duration) (val T, locked bool) {
+	debugBeforeLock(&l.mu, false)
+	locked = lockFor(clock, d, l.locker())
+	if locked {
+		debugAcquired(&l.mu, false)
+		val = l.value
+	}
+	return
+}
+
+// RLockContext read locks and gets the value, it returns ctx.Err() with zero value if ctx is done before the lock is acquired.
+func (l *RWLockedValue[T]) RLockContext(ctx context.Context) (val T, err error) {
+	debugBeforeLock(&l.mu, true)
+	if err = lockContext(ctx, l.rlocker()); err != nil {
+		return
+	}
+	debugAcquired(&l.mu, true)
+	return l.value, nil
+}
+
+// TryRLockFor return true with value if read lock successfully within d, return false with zero value if timeout.
+func (l *RWLockedValue[T]) TryRLockFor(d time.Duration) (val T, locked bool) {
+	return l.tryRLockFor(RealClock, d)
+}
+
+func (l *RWLockedValue[T]) tryRLockFor(clock Clock, d time.Duration) (val T, locked bool) {
+	debugBeforeLock(&l.mu, true)
+	locked = lockFor(clock, d, l.rlocker())
+	if locked {
+		debugAcquired(&l.mu, true)
+		val = l.value
+	}
+	return
+}
@@ -0,0 +1,232 @@
+package gsync
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func waitForWaiters(clock *ManualClock, n int) {
+	for clock.Waiters() != n {
+		runtime.Gosched()
+	}
+}
+
+func TestLockedValueLockContext(t *testing.T) {
+	t.Parallel()
+
+	lv := NewLockedValue(1)
+
+	v, err := lv.LockContext(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, v)
+
+	// locked by us, so the context must expire
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := lv.LockContext(ctx)
+		done <- err
+	}()
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+
+	// cancelled context never locks
+	v, err = lv.LockContext(ctx)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 0, v)
+
+	// callers which give up leave nothing waiting for the lock
+	for i := 0; i < 10; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		_, err = lv.LockContext(ctx)
+		cancel()
+		assert.Equal(t, context.DeadlineExceeded, err)
+	}
+	assert.Equal(t, 0, lv.mu.waiters.Len())
+
+	// the lock is handed over after unlock
+	go func() {
+		v, err := lv.LockContext(context.Background())
+		assert.Equal(t, 1, v)
+		done <- err
+		lv.Unlock()
+	}()
+	lv.Unlock()
+	assert.Nil(t, <-done)
+
+	v = lv.Lock()
+	assert.Equal(t, 1, v)
+	lv.Unlock()
+}
+
+func TestLockedValueTryLockFor(t *testing.T) {
+	t.Parallel()
+
+	clock := NewManualClock(time.Now())
+	lv := NewLockedValue(1)
+
+	v, locked := lv.tryLockFor(clock, time.Second)
+	assert.True(t, locked)
+	assert.Equal(t, 1, v)
+
+	_, locked = lv.tryLockFor(clock, 0)
+	assert.False(t, locked)
+
+	// timeout
+	done := make(chan bool)
+	go func() {
+		_, locked := lv.tryLockFor(clock, time.Second)
+		done <- locked
+	}()
+	waitForWaiters(clock, 1)
+	clock.Advance(time.Second)
+	assert.False(t, <-done)
+
+	// unlocked before timeout
+	go func() {
+		_, locked := lv.tryLockFor(clock, time.Second)
+		done <- locked
+	}()
+	waitForWaiters(clock, 1)
+	lv.Unlock()
+	assert.True(t, <-done)
+	assert.Equal(t, 0, clock.Waiters())
+	lv.Unlock()
+
+	v, locked = lv.TryLockFor(time.Millisecond)
+	assert.True(t, locked)
+	assert.Equal(t, 1, v)
+	lv.Unlock()
+}
+
+func TestRWLockedValueLockContext(t *testing.T) {
+	t.Parallel()
+
+	lv := NewRWLockedValue(1)
+
+	v, err := lv.RLockContext(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, v)
+
+	// readers do not block each other
+	v, err = lv.RLockContext(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, v)
+
+	// writer blocked by readers
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err = lv.LockContext(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// the writer which gave up does not block new readers
+	_, locked := lv.TryRLock()
+	assert.True(t, locked)
+	lv.RUnlock()
+
+	lv.RUnlock()
+	lv.RUnlock()
+
+	v, err = lv.LockContext(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, v)
+
+	// reader blocked by writer
+	ctx, cancel = context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := lv.RLockContext(ctx)
+		done <- err
+	}()
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+	lv.Unlock()
+
+	lv.SetValue(2)
+	v = lv.RLock()
+	assert.Equal(t, 2, v)
+	lv.RUnlock()
+
+	// a waiting writer is not starved by a stream of readers
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				lv.RLockCB(func(int) {})
+			}
+		}()
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = lv.LockContext(ctx)
+	assert.Nil(t, err)
+	lv.Unlock()
+	close(stop)
+	wg.Wait()
+}
+
+func TestRWLockedValueTryLockFor(t *testing.T) {
+	t.Parallel()
+
+	clock := NewManualClock(time.Now())
+	lv := NewRWLockedValue(1)
+
+	v, locked := lv.tryRLockFor(clock, time.Second)
+	assert.True(t, locked)
+	assert.Equal(t, 1, v)
+
+	// writer timeout
+	done := make(chan bool)
+	go func() {
+		_, locked := lv.tryLockFor(clock, time.Second)
+		done <- locked
+	}()
+	waitForWaiters(clock, 1)
+	clock.Advance(time.Second)
+	assert.False(t, <-done)
+
+	lv.RUnlock()
+
+	v, locked = lv.tryLockFor(clock, time.Second)
+	assert.True(t, locked)
+	assert.Equal(t, 1, v)
+
+	// reader timeout
+	go func() {
+		_, locked := lv.tryRLockFor(clock, time.Second)
+		done <- locked
+	}()
+	waitForWaiters(clock, 1)
+	clock.Advance(2 * time.Second)
+	assert.False(t, <-done)
+
+	// reader gets the lock after writer unlock
+	go func() {
+		_, locked := lv.tryRLockFor(clock, time.Second)
+		done <- locked
+	}()
+	waitForWaiters(clock, 1)
+	lv.Unlock()
+	assert.True(t, <-done)
+	lv.RUnlock()
+
+	_, locked = lv.TryRLockFor(time.Millisecond)
+	assert.True(t, locked)
+	lv.RUnlock()
+	_, locked = lv.TryLockFor(time.Millisecond)
+	assert.True(t, locked)
+	lv.Unlock()
+}
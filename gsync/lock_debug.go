@@ -0,0 +1,112 @@
+package gsync
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LockedValue and RWLockedValue check how they are locked when built with the gogdebug build tag,
+// e.g. `go test -tags gogdebug ./...`. The acquisition order of locks is tracked per goroutine,
+// and a LockDebugReport is sent to the handler set by SetLockDebugHandler when a lock order inversion,
+// a recursive lock or a lock held longer than the threshold set by SetLockHoldThreshold is found.
+// Locks are checked against the threshold by a background goroutine while they are held,
+// so a lock which is never released is reported too, each hold is reported at most once.
+// Without the build tag, the checks are compiled out and the functions below have no effect on locking.
+
+// LockDebugKind is the kind of problem found by the lock debugger.
+type LockDebugKind int
+
+const (
+	// LockOrderInversion means two locks are acquired in opposite orders by different code paths,
+	// which may deadlock when the paths run concurrently.
+	LockOrderInversion LockDebugKind = iota
+	// LockRecursive means a goroutine acquires a lock which it already holds.
+	LockRecursive
+	// LockHeldTooLong means a lock is held longer than the threshold.
+	LockHeldTooLong
+)
+
+func (k LockDebugKind) String() string {
+	switch k {
+	case LockOrderInversion:
+		return "lock order inversion"
+	case LockRecursive:
+		return "recursive lock"
+	case LockHeldTooLong:
+		return "lock held too long"
+	default:
+		return fmt.Sprintf("LockDebugKind(%d)", int(k))
+	}
+}
+
+// LockDebugReport describes a problem found by the lock debugger.
+type LockDebugReport struct {
+	Kind    LockDebugKind
+	Message string
+	// Stack is the stack of the goroutine which triggered the report.
+	Stack string
+}
+
+func (r LockDebugReport) String() string {
+	return fmt.Sprintf("gsync: %s: %s\n%s", r.Kind, r.Message, r.Stack)
+}
+
+// DefaultLockHoldThreshold is the default threshold of LockHeldTooLong reports.
+const DefaultLockHoldThreshold = time.Second
+
+var lockDebugConfig = struct {
+	mu        sync.Mutex
+	handler   func(LockDebugReport)
+	threshold time.Duration
+}{
+	threshold: DefaultLockHoldThreshold,
+}
+
+// SetLockDebugHandler sets the function called with each report, a nil handler restores the default one
+// which logs the report. It returns a function restoring the previous handler.
+func SetLockDebugHandler(handler func(LockDebugReport)) (restore func()) {
+	lockDebugConfig.mu.Lock()
+	defer lockDebugConfig.mu.Unlock()
+	prev := lockDebugConfig.handler
+	lockDebugConfig.handler = handler
+	return func() {
+		lockDebugConfig.mu.Lock()
+		defer lockDebugConfig.mu.Unlock()
+		lockDebugConfig.handler = prev
+	}
+}
+
+// SetLockHoldThreshold sets the hold time above which a LockHeldTooLong report is sent, d <= 0 disables the check.
+// It returns a function restoring the previous threshold.
+func SetLockHoldThreshold(d time.Duration) (restore func()) {
+	lockDebugConfig.mu.Lock()
+	defer lockDebugConfig.mu.Unlock()
+	prev := lockDebugConfig.threshold
+	lockDebugConfig.threshold = d
+	return func() {
+		lockDebugConfig.mu.Lock()
+		defer lockDebugConfig.mu.Unlock()
+		lockDebugConfig.threshold = prev
+	}
+}
+
+// FailOnLockDebugReport makes every report fail tb until the test finishes.
+// Since the handler is global, it should not be used by parallel tests.
+//
+// EXAMPLE:
+//
+//	func TestTransfer(t *testing.T) {
+//		gsync.FailOnLockDebugReport(t)
+//		...
+//	}
+func FailOnLockDebugReport(tb interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Cleanup(func())
+}) {
+	tb.Helper()
+	tb.Cleanup(SetLockDebugHandler(func(r LockDebugReport) {
+		tb.Errorf("%s", r)
+	}))
+}
@@ -0,0 +1,16 @@
+//go:build !gogdebug
+// +build !gogdebug
+
+package gsync
+
+// LockDebugEnabled reports whether the package is built with the gogdebug build tag.
+const LockDebugEnabled = false
+
+// lockDebugInfo is empty without the gogdebug build tag, so it costs nothing.
+type lockDebugInfo struct{}
+
+func debugBeforeLock(mu *rwMutex, read bool) {}
+
+func debugAcquired(mu *rwMutex, read bool) {}
+
+func debugReleased(mu *rwMutex, read bool) {}
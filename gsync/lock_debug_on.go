@@ -0,0 +1,316 @@
+//go:build gogdebug
+// +build gogdebug
+
+package gsync
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LockDebugEnabled reports whether the package is built with the gogdebug build tag.
+const LockDebugEnabled = true
+
+// lockDebugInfo gives each lock a stable id, the id of a lock is assigned on its first use.
+type lockDebugInfo struct {
+	once sync.Once
+	node *lockNode
+}
+
+// lockNode is only referenced by its lock, the edges of the lock are forgotten when it is collected.
+type lockNode struct {
+	id uint64
+	// pad keeps the node out of the tiny allocator, whose objects may never be finalized.
+	_ [16]byte
+}
+
+var nextLockID uint64
+
+func lockID(mu *rwMutex) uint64 {
+	d := &mu.debug
+	d.once.Do(func() {
+		d.node = &lockNode{id: atomic.AddUint64(&nextLockID, 1)}
+		runtime.SetFinalizer(d.node, func(n *lockNode) {
+			forgetLock(n.id)
+		})
+	})
+	return d.node.id
+}
+
+// forgetLock removes the edges of a collected lock.
+func forgetLock(id uint64) {
+	lockTracker.mu.Lock()
+	defer lockTracker.mu.Unlock()
+	delete(lockTracker.edges, id)
+	for from, tos := range lockTracker.edges {
+		delete(tos, id)
+		if len(tos) == 0 {
+			delete(lockTracker.edges, from)
+		}
+	}
+	for edge := range lockTracker.reported {
+		if edge.from == id || edge.to == id {
+			delete(lockTracker.reported, edge)
+		}
+	}
+}
+
+type heldLock struct {
+	id   uint64
+	read bool
+	at   time.Time
+	// reported means the lock has been reported as held too long by the watchdog.
+	reported bool
+}
+
+type lockOrderEdge struct {
+	from, to uint64
+}
+
+// lockTracker records the locks held by each goroutine and the order in which locks have been acquired,
+// an edge from A to B means B has been acquired while holding A.
+// Locks are identified by the ids given by lockID, the edges of a lock are removed when it is collected.
+var lockTracker = struct {
+	mu       sync.Mutex
+	held     map[int64][]heldLock
+	edges    map[uint64]map[uint64]string
+	reported map[lockOrderEdge]bool
+}{
+	held:     make(map[int64][]heldLock),
+	edges:    make(map[uint64]map[uint64]string),
+	reported: make(map[lockOrderEdge]bool),
+}
+
+var goroutinePrefix = []byte("goroutine ")
+
+// goid returns the id of the current goroutine parsed from its stack header.
+func goid() int64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, goroutinePrefix)
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseInt(string(b), 10, 64)
+	return id
+}
+
+// orderedBefore reports whether from has been held while acquiring to, directly or through other locks,
+// it returns the stack where the first edge of the path was established.
+func orderedBefore(from, to uint64, visited map[uint64]bool) (string, bool) {
+	visited[from] = true
+	for next, stack := range lockTracker.edges[from] {
+		if next == to {
+			return stack, true
+		}
+		if visited[next] {
+			continue
+		}
+		if _, ok := orderedBefore(next, to, visited); ok {
+			return stack, true
+		}
+	}
+	return "", false
+}
+
+func debugBeforeLock(mu *rwMutex, read bool) {
+	id, g := lockID(mu), goid()
+	var reports []LockDebugReport
+	var stack string
+	currentStack := func() string {
+		if stack == "" {
+			stack = string(debug.Stack())
+		}
+		return stack
+	}
+
+	lockTracker.mu.Lock()
+	for _, h := range lockTracker.held[g] {
+		if h.id == id {
+			reports = append(reports, LockDebugReport{
+				Kind:    LockRecursive,
+				Message: fmt.Sprintf("goroutine %d acquires lock #%d which it already holds", g, id),
+				Stack:   currentStack(),
+			})
+			continue
+		}
+		edge := lockOrderEdge{from: h.id, to: id}
+		if prev, ok := orderedBefore(id, h.id, make(map[uint64]bool)); ok && !lockTracker.reported[edge] {
+			lockTracker.reported[edge] = true
+			reports = append(reports, LockDebugReport{
+				Kind: LockOrderInversion,
+				Message: fmt.Sprintf("acquiring lock #%d while holding lock #%d, "+
+					"the opposite order was established at:\n%s", id, h.id, prev),
+				Stack: currentStack(),
+			})
+		}
+		if _, ok := lockTracker.edges[h.id][id]; !ok {
+			if lockTracker.edges[h.id] == nil {
+				lockTracker.edges[h.id] = make(map[uint64]string)
+			}
+			lockTracker.edges[h.id][id] = currentStack()
+		}
+	}
+	lockTracker.mu.Unlock()
+
+	for _, r := range reports {
+		reportLockDebug(r)
+	}
+}
+
+// watchdogOnce starts the watchdog when the first lock is acquired.
+var watchdogOnce sync.Once
+
+func debugAcquired(mu *rwMutex, read bool) {
+	watchdogOnce.Do(func() {
+		go watchLockHolds()
+	})
+	id, g := lockID(mu), goid()
+	lockTracker.mu.Lock()
+	defer lockTracker.mu.Unlock()
+	lockTracker.held[g] = append(lockTracker.held[g], heldLock{id: id, read: read, at: time.Now()})
+}
+
+// removeHeld removes the last lock matching id and read held by g.
+func removeHeld(g int64, id uint64, read bool) (heldLock, bool) {
+	held := lockTracker.held[g]
+	for i := len(held) - 1; i >= 0; i-- {
+		if held[i].id != id || held[i].read != read {
+			continue
+		}
+		h := held[i]
+		held = append(held[:i], held[i+1:]...)
+		if len(held) == 0 {
+			delete(lockTracker.held, g)
+		} else {
+			lockTracker.held[g] = held
+		}
+		return h, true
+	}
+	return heldLock{}, false
+}
+
+func debugReleased(mu *rwMutex, read bool) {
+	id, g := lockID(mu), goid()
+	lockTracker.mu.Lock()
+	h, ok := removeHeld(g, id, read)
+	if !ok {
+		// a sync.Mutex may be unlocked by another goroutine than the one which locked it
+		for other := range lockTracker.held {
+			if h, ok = removeHeld(other, id, read); ok {
+				break
+			}
+		}
+	}
+	lockTracker.mu.Unlock()
+	if !ok || h.reported {
+		return
+	}
+
+	threshold := lockHoldThreshold()
+	if hold := time.Since(h.at); threshold > 0 && hold > threshold {
+		reportLockDebug(LockDebugReport{
+			Kind:    LockHeldTooLong,
+			Message: fmt.Sprintf("lock #%d was held for %s, longer than %s", id, hold, threshold),
+			Stack:   string(debug.Stack()),
+		})
+	}
+}
+
+// watchLockHolds reports the locks held longer than the threshold while they are still held,
+// so that a lock which is never released, e.g. in a deadlock, is reported too.
+func watchLockHolds() {
+	for {
+		interval := lockHoldThreshold() / 2
+		if interval <= 0 {
+			// the check is disabled, wait for it to be enabled again
+			interval = DefaultLockHoldThreshold
+		} else if interval < time.Millisecond {
+			interval = time.Millisecond
+		}
+		time.Sleep(interval)
+		if threshold := lockHoldThreshold(); threshold > 0 {
+			checkLockHolds(threshold)
+		}
+	}
+}
+
+func checkLockHolds(threshold time.Duration) {
+	type lateLock struct {
+		g    int64
+		h    heldLock
+		hold time.Duration
+	}
+	var late []lateLock
+	now := time.Now()
+	lockTracker.mu.Lock()
+	for g, held := range lockTracker.held {
+		for i := range held {
+			if hold := now.Sub(held[i].at); !held[i].reported && hold > threshold {
+				held[i].reported = true
+				late = append(late, lateLock{g: g, h: held[i], hold: hold})
+			}
+		}
+	}
+	lockTracker.mu.Unlock()
+	if len(late) == 0 {
+		return
+	}
+
+	stacks := allStacks()
+	for _, l := range late {
+		reportLockDebug(LockDebugReport{
+			Kind: LockHeldTooLong,
+			Message: fmt.Sprintf("lock #%d has been held by goroutine %d for %s, longer than %s",
+				l.h.id, l.g, l.hold, threshold),
+			Stack: goroutineStack(stacks, l.g),
+		})
+	}
+}
+
+// allStacks returns the stacks of all goroutines.
+func allStacks() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// goroutineStack returns the stack of goroutine g in the stacks returned by allStacks.
+func goroutineStack(stacks []byte, g int64) string {
+	prefix := []byte("goroutine " + strconv.FormatInt(g, 10) + " [")
+	for _, stack := range bytes.Split(stacks, []byte("\n\n")) {
+		if bytes.HasPrefix(stack, prefix) {
+			return string(stack)
+		}
+	}
+	return ""
+}
+
+func lockHoldThreshold() time.Duration {
+	lockDebugConfig.mu.Lock()
+	defer lockDebugConfig.mu.Unlock()
+	return lockDebugConfig.threshold
+}
+
+func reportLockDebug(r LockDebugReport) {
+	lockDebugConfig.mu.Lock()
+	handler := lockDebugConfig.handler
+	lockDebugConfig.mu.Unlock()
+	if handler == nil {
+		log.Print(r)
+		return
+	}
+	handler(r)
+}
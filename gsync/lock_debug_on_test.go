@@ -0,0 +1,36 @@
+//go:build gogdebug
+// +build gogdebug
+
+package gsync
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockDebugForgetsCollectedLocks(t *testing.T) {
+	hasEdges := func(id uint64) bool {
+		lockTracker.mu.Lock()
+		defer lockTracker.mu.Unlock()
+		_, ok := lockTracker.edges[id]
+		return ok
+	}
+
+	a, b := NewLockedValue(0), NewLockedValue(0)
+	a.LockCB(func(int) {
+		b.LockCB(func(int) {})
+	})
+	id := lockID(&a.mu)
+	assert.NotEqual(t, id, lockID(&b.mu))
+	assert.True(t, hasEdges(id))
+
+	a, b = nil, nil
+	for i := 0; i < 100 && hasEdges(id); i++ {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+	assert.False(t, hasEdges(id))
+}
@@ -0,0 +1,151 @@
+//go:build gogdebug
+// +build gogdebug
+
+package gsync_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dashjay/gog/gsync"
+	"github.com/stretchr/testify/assert"
+)
+
+// collectLockDebugReports records the reports until the test finishes,
+// tests using it must not be parallel since the handler is global.
+func collectLockDebugReports(t *testing.T) *[]gsync.LockDebugReport {
+	var reports []gsync.LockDebugReport
+	t.Cleanup(gsync.SetLockDebugHandler(func(r gsync.LockDebugReport) {
+		reports = append(reports, r)
+	}))
+	return &reports
+}
+
+func TestLockDebugOrderInversion(t *testing.T) {
+	assert.True(t, gsync.LockDebugEnabled)
+
+	t.Run("direct", func(t *testing.T) {
+		reports := collectLockDebugReports(t)
+		a, b := gsync.NewLockedValue(0), gsync.NewRWLockedValue(0)
+		a.LockCB(func(int) {
+			b.RLockCB(func(int) {})
+		})
+		assert.Empty(t, *reports)
+
+		b.Update(func(*int) {
+			a.SetValue(1)
+		})
+		assert.Len(t, *reports, 1)
+		r := (*reports)[0]
+		assert.Equal(t, gsync.LockOrderInversion, r.Kind)
+		assert.Contains(t, r.Message, "the opposite order was established at")
+		assert.Contains(t, r.Stack, "TestLockDebugOrderInversion")
+
+		// reported only once
+		b.Update(func(*int) {
+			a.SetValue(2)
+		})
+		assert.Len(t, *reports, 1)
+	})
+
+	t.Run("indirect", func(t *testing.T) {
+		reports := collectLockDebugReports(t)
+		a, b, c := gsync.NewLockedValue(0), gsync.NewLockedValue(0), gsync.NewLockedValue(0)
+		a.LockCB(func(int) { b.LockCB(func(int) {}) })
+		b.LockCB(func(int) { c.LockCB(func(int) {}) })
+		assert.Empty(t, *reports)
+
+		c.Lock()
+		_, locked := a.TryLock()
+		assert.True(t, locked)
+		// TryLock can not deadlock
+		assert.Empty(t, *reports)
+		a.Unlock()
+		a.Lock()
+		a.Unlock()
+		c.Unlock()
+		assert.Len(t, *reports, 1)
+		assert.Equal(t, gsync.LockOrderInversion, (*reports)[0].Kind)
+	})
+}
+
+func TestLockDebugRecursive(t *testing.T) {
+	reports := collectLockDebugReports(t)
+	rw := gsync.NewRWLockedValue(0)
+	rw.RLock()
+	rw.RLock()
+	rw.RUnlock()
+	rw.RUnlock()
+	assert.Len(t, *reports, 1)
+	assert.Equal(t, gsync.LockRecursive, (*reports)[0].Kind)
+
+	// unlocking from another goroutine is allowed
+	lv := gsync.NewLockedValue(0)
+	lv.Lock()
+	done := make(chan struct{})
+	go func() {
+		lv.Unlock()
+		close(done)
+	}()
+	<-done
+	lv.Lock()
+	lv.Unlock()
+	assert.Len(t, *reports, 1)
+}
+
+func TestLockDebugHeldTooLong(t *testing.T) {
+	reports := make(chan gsync.LockDebugReport, 10)
+	t.Cleanup(gsync.SetLockDebugHandler(func(r gsync.LockDebugReport) {
+		// locks left held by other tests may be reported too
+		if strings.Contains(r.Stack, "TestLockDebugHeldTooLong") {
+			reports <- r
+		}
+	}))
+	t.Cleanup(gsync.SetLockHoldThreshold(time.Millisecond))
+
+	lv := gsync.NewLockedValue(0)
+	lv.Update(func(*int) {
+		// the watchdog reports the lock while it is still held
+		r := <-reports
+		assert.Equal(t, gsync.LockHeldTooLong, r.Kind)
+		assert.Contains(t, r.Message, "longer than 1ms")
+	})
+	lv.SetValue(1)
+	// the hold is not reported again on release
+	select {
+	case r := <-reports:
+		t.Fatalf("unexpected report: %s", r)
+	default:
+	}
+}
+
+type fakeTB struct {
+	errors   []string
+	cleanups []func()
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+func TestFailOnLockDebugReport(t *testing.T) {
+	tb := &fakeTB{}
+	gsync.FailOnLockDebugReport(tb)
+	lv := gsync.NewLockedValue(0)
+	lv.Lock()
+	_, _ = lv.TryLockFor(time.Millisecond)
+	lv.Unlock()
+	for _, fn := range tb.cleanups {
+		fn()
+	}
+	assert.Len(t, tb.errors, 1)
+	assert.Contains(t, tb.errors[0], "gsync: recursive lock: ")
+}
@@ -0,0 +1,425 @@
+package gsync
+
+import (
+	"expvar"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultStackSampleEvery is the stack sampling rate used when LockStatsConfig.StackSampleEvery is 0.
+const DefaultStackSampleEvery = 64
+
+// lockStatsBounds are the upper bounds of the buckets of lock wait and hold histograms.
+var lockStatsBounds = []time.Duration{
+	time.Microsecond,
+	10 * time.Microsecond,
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+}
+
+const maxStackDepth = 32
+
+// DurationHistogram is a snapshot of a histogram of durations.
+type DurationHistogram struct {
+	// Bounds are the inclusive upper bounds of the buckets.
+	Bounds []time.Duration
+	// Counts has one more element than Bounds, the last one counts the durations greater than all bounds.
+	Counts []uint64
+	Sum    time.Duration
+	Max    time.Duration
+}
+
+// Count returns the number of observed durations.
+func (h DurationHistogram) Count() uint64 {
+	var n uint64
+	for _, c := range h.Counts {
+		n += c
+	}
+	return n
+}
+
+// Mean returns the average of observed durations, it returns 0 if there is none.
+func (h DurationHistogram) Mean() time.Duration {
+	n := h.Count()
+	if n == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(n)
+}
+
+// Quantile returns an upper estimation of the q-quantile (0 <= q <= 1),
+// which is the upper bound of the bucket containing it, capped at Max.
+func (h DurationHistogram) Quantile(q float64) time.Duration {
+	n := h.Count()
+	if n == 0 {
+		return 0
+	}
+	rank := uint64(q * float64(n))
+	if rank == 0 {
+		rank = 1
+	}
+	var seen uint64
+	for i, c := range h.Counts {
+		seen += c
+		if seen < rank {
+			continue
+		}
+		if i < len(h.Bounds) && h.Bounds[i] < h.Max {
+			return h.Bounds[i]
+		}
+		break
+	}
+	return h.Max
+}
+
+type durationHistogram struct {
+	counts [8]uint64
+	sum    int64
+	max    int64
+}
+
+func (h *durationHistogram) observe(d time.Duration) {
+	i := sort.Search(len(lockStatsBounds), func(i int) bool { return d <= lockStatsBounds[i] })
+	atomic.AddUint64(&h.counts[i], 1)
+	atomic.AddInt64(&h.sum, int64(d))
+	for {
+		m := atomic.LoadInt64(&h.max)
+		if int64(d) <= m || atomic.CompareAndSwapInt64(&h.max, m, int64(d)) {
+			return
+		}
+	}
+}
+
+func (h *durationHistogram) snapshot() DurationHistogram {
+	s := DurationHistogram{
+		Bounds: append([]time.Duration(nil), lockStatsBounds...),
+		Counts: make([]uint64, len(h.counts)),
+		Sum:    time.Duration(atomic.LoadInt64(&h.sum)),
+		Max:    time.Duration(atomic.LoadInt64(&h.max)),
+	}
+	for i := range h.counts {
+		s.Counts[i] = atomic.LoadUint64(&h.counts[i])
+	}
+	return s
+}
+
+// LockStats is a snapshot of the statistics of an instrumented LockedValue or RWLockedValue.
+type LockStats struct {
+	Name string
+	// Acquisitions and RAcquisitions are the numbers of write and read lock acquisitions.
+	Acquisitions  uint64
+	RAcquisitions uint64
+	// Contended is the number of acquisitions which had to wait for the lock.
+	Contended uint64
+	// Wait is the histogram of time spent waiting for the lock, uncontended acquisitions count as 0.
+	Wait DurationHistogram
+	// Hold is the histogram of time the write lock was held,
+	// read locks are not included since they can be held by several goroutines at once.
+	Hold DurationHistogram
+	// SlowestHold and SlowestStack are the hold time and the stack where the lock was acquired
+	// of the slowest sampled write lock holder.
+	SlowestHold  time.Duration
+	SlowestStack string
+}
+
+// LockStatsConfig configures the instrumentation of a LockedValue or RWLockedValue.
+type LockStatsConfig struct {
+	// Name registers the stats in Registry, values with the same name share their stats,
+	// the stats are not registered if Name is empty.
+	Name string
+	// Registry is where the stats are registered, DefaultLockRegistry is used if nil.
+	Registry *LockRegistry
+	// StackSampleEvery records the stack of one in StackSampleEvery write acquisitions,
+	// 0 means DefaultStackSampleEvery and a negative value disables stack sampling.
+	StackSampleEvery int
+	// Clock is the time source, RealClock is used if nil.
+	Clock Clock
+}
+
+type lockRecorder struct {
+	name        string
+	clock       Clock
+	sampleEvery uint64
+
+	acquisitions  uint64
+	racquisitions uint64
+	contended     uint64
+	samples       uint64
+	wait          durationHistogram
+	hold          durationHistogram
+
+	mu           sync.Mutex
+	slowestHold  time.Duration
+	slowestStack []uintptr
+}
+
+func newLockRecorder(cfg LockStatsConfig) *lockRecorder {
+	r := &lockRecorder{name: cfg.Name, clock: cfg.Clock}
+	if r.clock == nil {
+		r.clock = RealClock
+	}
+	if cfg.StackSampleEvery == 0 {
+		cfg.StackSampleEvery = DefaultStackSampleEvery
+	}
+	if cfg.StackSampleEvery > 0 {
+		r.sampleEvery = uint64(cfg.StackSampleEvery)
+	}
+	return r
+}
+
+func (r *lockRecorder) offerSlowest(hold time.Duration, pcs []uintptr) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if hold > r.slowestHold || r.slowestStack == nil {
+		r.slowestHold, r.slowestStack = hold, pcs
+	}
+}
+
+func (r *lockRecorder) stats() LockStats {
+	r.mu.Lock()
+	slowestHold, slowestStack := r.slowestHold, r.slowestStack
+	r.mu.Unlock()
+	return LockStats{
+		Name:          r.name,
+		Acquisitions:  atomic.LoadUint64(&r.acquisitions),
+		RAcquisitions: atomic.LoadUint64(&r.racquisitions),
+		Contended:     atomic.LoadUint64(&r.contended),
+		Wait:          r.wait.snapshot(),
+		Hold:          r.hold.snapshot(),
+		SlowestHold:   slowestHold,
+		SlowestStack:  formatStack(slowestStack),
+	}
+}
+
+// formatStack formats pcs like debug.Stack, leading frames inside gsync are skipped.
+func formatStack(pcs []uintptr) string {
+	if len(pcs) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	inside := true
+	for {
+		frame, more := frames.Next()
+		if inside && strings.HasPrefix(frame.Function, "github.com/dashjay/gog/gsync.") {
+			if !more {
+				break
+			}
+			continue
+		}
+		inside = false
+		fmt.Fprintf(&sb, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
+
+// lockProbe wraps the lock of an instrumented value and records its stats.
+type lockProbe struct {
+	rec  *lockRecorder
+	mu   cancelableLocker
+	read bool
+	// lockedAt and pcs describe the current write lock holder, they are protected by mu.
+	lockedAt time.Time
+	pcs      []uintptr
+}
+
+func newLockProbe(rec *lockRecorder, mu cancelableLocker, read bool) *lockProbe {
+	return &lockProbe{rec: rec, mu: mu, read: read}
+}
+
+func (p *lockProbe) TryLock() bool {
+	if !p.mu.TryLock() {
+		return false
+	}
+	p.acquired(time.Time{})
+	return true
+}
+
+func (p *lockProbe) Lock() {
+	if p.TryLock() {
+		return
+	}
+	start := p.rec.clock.Now()
+	p.mu.Lock()
+	p.acquired(start)
+}
+
+func (p *lockProbe) LockUntil(done <-chan struct{}, timeout <-chan time.Time) bool {
+	if p.TryLock() {
+		return true
+	}
+	start := p.rec.clock.Now()
+	if !p.mu.LockUntil(done, timeout) {
+		return false
+	}
+	p.acquired(start)
+	return true
+}
+
+func (p *lockProbe) Unlock() {
+	if !p.read {
+		hold := p.rec.clock.Now().Sub(p.lockedAt)
+		p.rec.hold.observe(hold)
+		if p.pcs != nil {
+			p.rec.offerSlowest(hold, p.pcs)
+			p.pcs = nil
+		}
+	}
+	p.mu.Unlock()
+}
+
+// acquired records an acquisition, start is zero if the lock was acquired without waiting.
+func (p *lockProbe) acquired(start time.Time) {
+	r := p.rec
+	now := r.clock.Now()
+	if start.IsZero() {
+		r.wait.observe(0)
+	} else {
+		atomic.AddUint64(&r.contended, 1)
+		r.wait.observe(now.Sub(start))
+	}
+	if p.read {
+		atomic.AddUint64(&r.racquisitions, 1)
+		return
+	}
+	atomic.AddUint64(&r.acquisitions, 1)
+	p.lockedAt = now
+	if r.sampleEvery > 0 && atomic.AddUint64(&r.samples, 1)%r.sampleEvery == 0 {
+		pcs := make([]uintptr, maxStackDepth)
+		p.pcs = pcs[:runtime.Callers(3, pcs)]
+	}
+}
+
+// LockRegistry collects the stats of named instrumented values, so they can be dumped to logs or expvar.
+// The zero value is ready to use.
+type LockRegistry struct {
+	mu        sync.Mutex
+	recorders map[string]*lockRecorder
+}
+
+// DefaultLockRegistry is the registry used when LockStatsConfig.Registry is nil.
+var DefaultLockRegistry = NewLockRegistry()
+
+// NewLockRegistry creates a new LockRegistry.
+func NewLockRegistry() *LockRegistry {
+	return &LockRegistry{}
+}
+
+// recorder returns the recorder registered with cfg.Name, or registers a new one.
+func (r *LockRegistry) recorder(cfg LockStatsConfig) *lockRecorder {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rec, ok := r.recorders[cfg.Name]; ok {
+		return rec
+	}
+	if r.recorders == nil {
+		r.recorders = make(map[string]*lockRecorder)
+	}
+	rec := newLockRecorder(cfg)
+	r.recorders[cfg.Name] = rec
+	return rec
+}
+
+// Stats returns the stats of all registered values sorted by name.
+func (r *LockRegistry) Stats() []LockStats {
+	r.mu.Lock()
+	recs := make([]*lockRecorder, 0, len(r.recorders))
+	for _, rec := range r.recorders {
+		recs = append(recs, rec)
+	}
+	r.mu.Unlock()
+	sort.Slice(recs, func(i, j int) bool { return recs[i].name < recs[j].name })
+	out := make([]LockStats, len(recs))
+	for i, rec := range recs {
+		out[i] = rec.stats()
+	}
+	return out
+}
+
+// Dump writes a human-readable summary of all registered values to w, one line per value,
+// followed by the slowest holder's stack if it has been sampled.
+func (r *LockRegistry) Dump(w io.Writer) error {
+	for _, s := range r.Stats() {
+		_, err := fmt.Fprintf(w, "lock %s: acquisitions=%d racquisitions=%d contended=%d "+
+			"wait_mean=%s wait_p99=%s wait_max=%s hold_mean=%s hold_p99=%s hold_max=%s\n",
+			s.Name, s.Acquisitions, s.RAcquisitions, s.Contended,
+			s.Wait.Mean(), s.Wait.Quantile(0.99), s.Wait.Max,
+			s.Hold.Mean(), s.Hold.Quantile(0.99), s.Hold.Max)
+		if err != nil {
+			return err
+		}
+		if s.SlowestStack != "" {
+			_, err = fmt.Fprintf(w, "slowest holder (%s):\n%s", s.SlowestHold, s.SlowestStack)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Publish exports the stats of all registered values as an expvar named name,
+// like expvar.Publish, it panics if name is already published.
+func (r *LockRegistry) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return r.Stats()
+	}))
+}
+
+func lockRecorderFor(cfg LockStatsConfig) *lockRecorder {
+	if cfg.Name == "" {
+		return newLockRecorder(cfg)
+	}
+	reg := cfg.Registry
+	if reg == nil {
+		reg = DefaultLockRegistry
+	}
+	return reg.recorder(cfg)
+}
+
+// NewInstrumentedLockedValue returns a new LockedValue which records the stats of its lock,
+// the stats can be read by Stats or through the registry in cfg.
+// The instrumentation costs several clock reads per acquisition and is meant for finding contended locks.
+func NewInstrumentedLockedValue[T any](value T, cfg LockStatsConfig) *LockedValue[T] {
+	l := &LockedValue[T]{value: value}
+	l.probe = newLockProbe(lockRecorderFor(cfg), &l.mu, false)
+	return l
+}
+
+// Stats returns the stats of l, it returns the zero LockStats if l is not instrumented.
+func (l *LockedValue[T]) Stats() LockStats {
+	if l.probe == nil {
+		return LockStats{}
+	}
+	return l.probe.rec.stats()
+}
+
+// NewInstrumentedRWLockedValue returns a new RWLockedValue which records the stats of its lock,
+// see NewInstrumentedLockedValue.
+func NewInstrumentedRWLockedValue[T any](value T, cfg LockStatsConfig) *RWLockedValue[T] {
+	l := &RWLockedValue[T]{value: value}
+	rec := lockRecorderFor(cfg)
+	l.probe = newLockProbe(rec, &l.mu, false)
+	l.rprobe = newLockProbe(rec, (*rlocker)(&l.mu), true)
+	return l
+}
+
+// Stats returns the stats of l, it returns the zero LockStats if l is not instrumented.
+func (l *RWLockedValue[T]) Stats() LockStats {
+	if l.probe == nil {
+		return LockStats{}
+	}
+	return l.probe.rec.stats()
+}
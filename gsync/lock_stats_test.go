@@ -0,0 +1,202 @@
+package gsync_test
+
+import (
+	"bytes"
+	"context"
+	"expvar"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dashjay/gog/gsync"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingClock counts the calls to Now, so tests can tell when a lock starts waiting.
+type countingClock struct {
+	*gsync.ManualClock
+	nows int32
+}
+
+func (c *countingClock) Now() time.Time {
+	atomic.AddInt32(&c.nows, 1)
+	return c.ManualClock.Now()
+}
+
+func TestDurationHistogram(t *testing.T) {
+	t.Parallel()
+
+	h := gsync.DurationHistogram{
+		Bounds: []time.Duration{time.Millisecond, 10 * time.Millisecond},
+		Counts: []uint64{8, 1, 1},
+		Sum:    100 * time.Millisecond,
+		Max:    70 * time.Millisecond,
+	}
+	assert.Equal(t, uint64(10), h.Count())
+	assert.Equal(t, 10*time.Millisecond, h.Mean())
+	assert.Equal(t, time.Millisecond, h.Quantile(0.5))
+	assert.Equal(t, 10*time.Millisecond, h.Quantile(0.9))
+	assert.Equal(t, 70*time.Millisecond, h.Quantile(1))
+	assert.Equal(t, time.Duration(0), gsync.DurationHistogram{}.Quantile(0.5))
+}
+
+func TestInstrumentedLockedValue(t *testing.T) {
+	t.Parallel()
+
+	t.Run("not instrumented", func(t *testing.T) {
+		lv := gsync.NewLockedValue(1)
+		lv.SetValue(2)
+		assert.Equal(t, gsync.LockStats{}, lv.Stats())
+		assert.Equal(t, gsync.LockStats{}, gsync.NewRWLockedValue(1).Stats())
+	})
+
+	t.Run("hold", func(t *testing.T) {
+		clock := gsync.NewManualClock(time.Now())
+		lv := gsync.NewInstrumentedLockedValue(0, gsync.LockStatsConfig{StackSampleEvery: 1, Clock: clock})
+		lv.Lock()
+		clock.Advance(5 * time.Millisecond)
+		lv.Unlock()
+		lv.Update(func(v *int) {
+			clock.Advance(20 * time.Millisecond)
+			*v++
+		})
+		_, locked := lv.TryLock()
+		assert.True(t, locked)
+		lv.Unlock()
+		assert.Equal(t, 1, lv.Load())
+
+		s := lv.Stats()
+		assert.Equal(t, uint64(4), s.Acquisitions)
+		assert.Equal(t, uint64(0), s.Contended)
+		assert.Equal(t, uint64(4), s.Wait.Count())
+		assert.Equal(t, time.Duration(0), s.Wait.Max)
+		assert.Equal(t, uint64(4), s.Hold.Count())
+		assert.Equal(t, 25*time.Millisecond, s.Hold.Sum)
+		assert.Equal(t, 20*time.Millisecond, s.Hold.Max)
+		assert.Equal(t, 20*time.Millisecond, s.SlowestHold)
+		assert.Contains(t, s.SlowestStack, "TestInstrumentedLockedValue")
+		assert.NotContains(t, s.SlowestStack, "gsync.(*LockedValue")
+	})
+
+	t.Run("contended", func(t *testing.T) {
+		clock := &countingClock{ManualClock: gsync.NewManualClock(time.Now())}
+		lv := gsync.NewInstrumentedLockedValue(0, gsync.LockStatsConfig{Clock: clock, StackSampleEvery: -1})
+		lv.Lock()
+		done := make(chan struct{})
+		go func() {
+			lv.Update(func(v *int) { *v++ })
+			close(done)
+		}()
+		// the clock is read once when the lock is acquired, and once more when the waiter starts waiting
+		for atomic.LoadInt32(&clock.nows) != 2 {
+			runtime.Gosched()
+		}
+		clock.Advance(time.Millisecond)
+		lv.Unlock()
+		<-done
+
+		s := lv.Stats()
+		assert.Equal(t, uint64(2), s.Acquisitions)
+		assert.Equal(t, uint64(1), s.Contended)
+		assert.Equal(t, time.Millisecond, s.Wait.Max)
+		assert.Equal(t, "", s.SlowestStack)
+	})
+
+	t.Run("rw", func(t *testing.T) {
+		clock := gsync.NewManualClock(time.Now())
+		lv := gsync.NewInstrumentedRWLockedValue(0, gsync.LockStatsConfig{Clock: clock})
+		lv.RLock()
+		lv.RUnlock()
+		assert.Equal(t, 0, lv.Load())
+		assert.Equal(t, 0, gsync.WithRLock(lv, func(v int) int { return v }))
+		_, err := lv.RLockContext(context.Background())
+		assert.Nil(t, err)
+		lv.RUnlock()
+		lv.Update(func(v *int) {
+			clock.Advance(time.Millisecond)
+			*v++
+		})
+
+		s := lv.Stats()
+		assert.Equal(t, uint64(4), s.RAcquisitions)
+		assert.Equal(t, uint64(1), s.Acquisitions)
+		assert.Equal(t, uint64(1), s.Hold.Count())
+		assert.Equal(t, time.Millisecond, s.Hold.Max)
+	})
+
+	t.Run("rw contended lock context", func(t *testing.T) {
+		clock := &countingClock{ManualClock: gsync.NewManualClock(time.Now())}
+		lv := gsync.NewInstrumentedRWLockedValue(0, gsync.LockStatsConfig{Clock: clock, StackSampleEvery: -1})
+		lv.RLock()
+		done := make(chan error)
+		go func() {
+			_, err := lv.LockContext(context.Background())
+			done <- err
+		}()
+		// the clock is read once when the read lock is acquired, and once more when the writer starts waiting
+		for atomic.LoadInt32(&clock.nows) != 2 {
+			runtime.Gosched()
+		}
+		clock.Advance(50 * time.Millisecond)
+		lv.RUnlock()
+		assert.Nil(t, <-done)
+		lv.Unlock()
+
+		s := lv.Stats()
+		assert.Equal(t, uint64(1), s.Acquisitions)
+		assert.Equal(t, uint64(1), s.Contended)
+		assert.Equal(t, 50*time.Millisecond, s.Wait.Max)
+	})
+
+	t.Run("abandoned lock context", func(t *testing.T) {
+		lv := gsync.NewInstrumentedLockedValue(0, gsync.LockStatsConfig{StackSampleEvery: -1})
+		lv.Lock()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		_, err := lv.LockContext(ctx)
+		assert.Equal(t, context.DeadlineExceeded, err)
+		lv.Unlock()
+
+		// only the first Lock is recorded
+		s := lv.Stats()
+		assert.Equal(t, uint64(1), s.Acquisitions)
+		assert.Equal(t, uint64(1), s.Hold.Count())
+	})
+}
+
+func TestLockRegistry(t *testing.T) {
+	t.Parallel()
+
+	reg := gsync.NewLockRegistry()
+	a1 := gsync.NewInstrumentedLockedValue(0, gsync.LockStatsConfig{Name: "a", Registry: reg})
+	a2 := gsync.NewInstrumentedRWLockedValue("", gsync.LockStatsConfig{Name: "a", Registry: reg})
+	b := gsync.NewInstrumentedLockedValue(0, gsync.LockStatsConfig{Name: "b", Registry: reg, StackSampleEvery: 1})
+	a1.SetValue(1)
+	a2.SetValue("x")
+	b.SetValue(1)
+
+	stats := reg.Stats()
+	assert.Len(t, stats, 2)
+	assert.Equal(t, "a", stats[0].Name)
+	assert.Equal(t, uint64(2), stats[0].Acquisitions)
+	assert.Equal(t, stats[0], a1.Stats())
+	assert.Equal(t, "b", stats[1].Name)
+	assert.Equal(t, uint64(1), stats[1].Acquisitions)
+
+	var buf bytes.Buffer
+	assert.Nil(t, reg.Dump(&buf))
+	assert.Contains(t, buf.String(), "lock a: acquisitions=2 ")
+	assert.Contains(t, buf.String(), "lock b: acquisitions=1 ")
+	assert.Contains(t, buf.String(), "slowest holder")
+	assert.Contains(t, buf.String(), "TestLockRegistry")
+
+	if expvar.Get("gsync_test_locks") == nil {
+		reg.Publish("gsync_test_locks")
+	} else {
+		// published by a previous run with -count
+		assert.Panics(t, func() { reg.Publish("gsync_test_locks") })
+		return
+	}
+	assert.Contains(t, expvar.Get("gsync_test_locks").String(), `"Name":"b"`)
+}
@@ -1,7 +1,5 @@
 package gsync
 
-import "sync"
-
 type noCopy struct {
 }
 
@@ -12,7 +10,8 @@ func (n noCopy) Unlock() {}
 // LockedValue is a wrapper wrapping a value protect by a mutex
 type LockedValue[T any] struct {
 	value T
-	mu    sync.Mutex
+	mu    rwMutex
+	probe *lockProbe
 	_     noCopy
 }
 
@@ -21,27 +20,67 @@ func NewLockedValue[T any](value T) *LockedValue[T] {
 	return &LockedValue[T]{value: value}
 }
 
+func (l *LockedValue[T]) lock() {
+	debugBeforeLock(&l.mu, false)
+	if l.probe != nil {
+		l.probe.Lock()
+	} else {
+		l.mu.Lock()
+	}
+	debugAcquired(&l.mu, false)
+}
+
+func (l *LockedValue[T]) unlock() {
+	debugReleased(&l.mu, false)
+	if l.probe != nil {
+		l.probe.Unlock()
+		return
+	}
+	l.mu.Unlock()
+}
+
+func (l *LockedValue[T]) tryLock() bool {
+	var locked bool
+	if l.probe != nil {
+		locked = l.probe.TryLock()
+	} else {
+		locked = l.mu.TryLock()
+	}
+	if locked {
+		debugAcquired(&l.mu, false)
+	}
+	return locked
+}
+
+// locker returns the lock of l, which records stats if l is instrumented.
+func (l *LockedValue[T]) locker() cancelableLocker {
+	if l.probe != nil {
+		return l.probe
+	}
+	return &l.mu
+}
+
 // LockCB is a shortcut for l.mu.Lock() and defer l.mu.Unlock()
 func (l *LockedValue[T]) LockCB(cb func(T)) {
-	l.mu.Lock()
+	l.lock()
 	cb(l.value)
-	l.mu.Unlock()
+	l.unlock()
 }
 
 // Lock and get the value
 func (l *LockedValue[T]) Lock() T {
-	l.mu.Lock()
+	l.lock()
 	return l.value
 }
 
 // Unlock then the value is unprotected
 func (l *LockedValue[T]) Unlock() {
-	l.mu.Unlock()
+	l.unlock()
 }
 
 // TryLock return true with value if lock successfully, return false with zero value if lock failed
 func (l *LockedValue[T]) TryLock() (val T, locked bool) {
-	locked = l.mu.TryLock()
+	locked = l.tryLock()
 	if locked {
 		val = l.value
 	}
@@ -50,16 +89,56 @@ func (l *LockedValue[T]) TryLock() (val T, locked bool) {
 
 // SetValue can modify the underlying value with protection
 func (l *LockedValue[T]) SetValue(value T) {
-	l.mu.Lock()
+	l.lock()
 	l.value = value
-	l.mu.Unlock()
+	l.unlock()
+}
+
+// Update calls f with a pointer to the underlying value under the lock,
+// so f can modify the value in place (e.g. fields of a struct or entries of a map).
+func (l *LockedValue[T]) Update(f func(*T)) {
+	l.lock()
+	defer l.unlock()
+	f(&l.value)
+}
+
+// Load returns a copy of the underlying value taken under the lock.
+func (l *LockedValue[T]) Load() T {
+	l.lock()
+	defer l.unlock()
+	return l.value
+}
+
+// Swap replaces the underlying value with value and returns the old one.
+func (l *LockedValue[T]) Swap(value T) (old T) {
+	l.lock()
+	old, l.value = l.value, value
+	l.unlock()
+	return old
+}
+
+// With calls f with a pointer to the value under the lock of l and returns the result of f.
+//
+// EXAMPLE:
+//
+//	lv := gsync.NewLockedValue(map[string]int{})
+//	n := gsync.With(lv, func(m *map[string]int) int {
+//		(*m)["a"]++
+//		return len(*m)
+//	}) 👉 1
+func With[T, R any](l *LockedValue[T], f func(*T) R) R {
+	l.lock()
+	defer l.unlock()
+	return f(&l.value)
 }
 
 // RWLockedValue is a wrapper wrapping a value protect by a RWMutex
 type RWLockedValue[T any] struct {
-	value T
-	mu    sync.RWMutex
-	_     noCopy
+	value  T
+	mu     rwMutex
+	probe  *lockProbe
+	rprobe *lockProbe
+	_      noCopy
 }
 
 // NewRWLockedValue returns a new RWLockedValue
@@ -67,34 +146,114 @@ func NewRWLockedValue[T any](value T) *RWLockedValue[T] {
 	return &RWLockedValue[T]{value: value}
 }
 
+func (l *RWLockedValue[T]) lock() {
+	debugBeforeLock(&l.mu, false)
+	if l.probe != nil {
+		l.probe.Lock()
+	} else {
+		l.mu.Lock()
+	}
+	debugAcquired(&l.mu, false)
+}
+
+func (l *RWLockedValue[T]) unlock() {
+	debugReleased(&l.mu, false)
+	if l.probe != nil {
+		l.probe.Unlock()
+		return
+	}
+	l.mu.Unlock()
+}
+
+func (l *RWLockedValue[T]) tryLock() bool {
+	var locked bool
+	if l.probe != nil {
+		locked = l.probe.TryLock()
+	} else {
+		locked = l.mu.TryLock()
+	}
+	if locked {
+		debugAcquired(&l.mu, false)
+	}
+	return locked
+}
+
+func (l *RWLockedValue[T]) rlock() {
+	debugBeforeLock(&l.mu, true)
+	if l.rprobe != nil {
+		l.rprobe.Lock()
+	} else {
+		(*rlocker)(&l.mu).Lock()
+	}
+	debugAcquired(&l.mu, true)
+}
+
+func (l *RWLockedValue[T]) runlock() {
+	debugReleased(&l.mu, true)
+	if l.rprobe != nil {
+		l.rprobe.Unlock()
+		return
+	}
+	(*rlocker)(&l.mu).Unlock()
+}
+
+func (l *RWLockedValue[T]) tryRLock() bool {
+	var locked bool
+	if l.rprobe != nil {
+		locked = l.rprobe.TryLock()
+	} else {
+		locked = (*rlocker)(&l.mu).TryLock()
+	}
+	if locked {
+		debugAcquired(&l.mu, true)
+	}
+	return locked
+}
+
+// locker returns the write lock of l, which records stats if l is instrumented.
+func (l *RWLockedValue[T]) locker() cancelableLocker {
+	if l.probe != nil {
+		return l.probe
+	}
+	return &l.mu
+}
+
+// rlocker returns the read lock of l, which records stats if l is instrumented.
+func (l *RWLockedValue[T]) rlocker() cancelableLocker {
+	if l.rprobe != nil {
+		return l.rprobe
+	}
+	return (*rlocker)(&l.mu)
+}
+
 // Lock and get the value
 func (l *RWLockedValue[T]) Lock() T {
-	l.mu.Lock()
+	l.lock()
 	return l.value
 }
 
 // Unlock then the value is unprotected
 func (l *RWLockedValue[T]) Unlock() {
-	l.mu.Unlock()
+	l.unlock()
 }
 
-// LockCB is a shortcut for l.mu.RLock() and defer l.mu.RUnlock()
+// LockCB is a shortcut for (*rlocker)(&l.mu).Lock() and defer (*rlocker)(&l.mu).Unlock()
 func (l *RWLockedValue[T]) LockCB(cb func(T)) {
-	l.mu.Lock()
+	l.lock()
 	cb(l.value)
-	l.mu.Unlock()
+	l.unlock()
 }
 
-// RLockCB is a shortcut for l.mu.RLock() and defer l.mu.RUnlock()
+// RLockCB is a shortcut for (*rlocker)(&l.mu).Lock() and defer (*rlocker)(&l.mu).Unlock()
 func (l *RWLockedValue[T]) RLockCB(cb func(T)) {
-	l.mu.RLock()
+	l.rlock()
 	cb(l.value)
-	l.mu.RUnlock()
+	l.runlock()
 }
 
 // TryLock return true with value if lock successfully, return false with zero value if lock failed
 func (l *RWLockedValue[T]) TryLock() (val T, locked bool) {
-	locked = l.mu.TryLock()
+	locked = l.tryLock()
 	if locked {
 		val = l.value
 	}
@@ -105,18 +264,18 @@ func (l *RWLockedValue[T]) TryLock() (val T, locked bool) {
 // Gentleman's agreement: RLock means you should not modify the value,
 // We cannot force a declaration that the return value cannot be modified
 func (l *RWLockedValue[T]) RLock() T {
-	l.mu.RLock()
+	l.rlock()
 	return l.value
 }
 
 // RUnlock then the value is unprotected
 func (l *RWLockedValue[T]) RUnlock() {
-	l.mu.RUnlock()
+	l.runlock()
 }
 
 // TryRLock return true with value if lock successfully, return false with zero value if lock failed
 func (l *RWLockedValue[T]) TryRLock() (val T, locked bool) {
-	locked = l.mu.TryRLock()
+	locked = l.tryRLock()
 	if locked {
 		val = l.value
 	}
@@ -125,7 +284,53 @@ func (l *RWLockedValue[T]) TryRLock() (val T, locked bool) {
 
 // SetValue can modify the underlying value with protection
 func (l *RWLockedValue[T]) SetValue(value T) {
-	l.mu.Lock()
+	l.lock()
 	l.value = value
-	l.mu.Unlock()
+	l.unlock()
+}
+
+// Update calls f with a pointer to the underlying value under the write lock,
+// so f can modify the value in place (e.g. fields of a struct or entries of a map).
+func (l *RWLockedValue[T]) Update(f func(*T)) {
+	l.lock()
+	defer l.unlock()
+	f(&l.value)
+}
+
+// RLockPtrCB calls cb with a pointer to the underlying value under the read lock,
+// it avoids copying large values, unlike RLockCB which receives a copy.
+// Gentleman's agreement: cb should not modify the value through the pointer.
+func (l *RWLockedValue[T]) RLockPtrCB(cb func(*T)) {
+	l.rlock()
+	defer l.runlock()
+	cb(&l.value)
+}
+
+// Load returns a copy of the underlying value taken under the read lock.
+func (l *RWLockedValue[T]) Load() T {
+	l.rlock()
+	defer l.runlock()
+	return l.value
+}
+
+// Swap replaces the underlying value with value and returns the old one.
+func (l *RWLockedValue[T]) Swap(value T) (old T) {
+	l.lock()
+	old, l.value = l.value, value
+	l.unlock()
+	return old
+}
+
+// WithRW calls f with a pointer to the value under the write lock of l and returns the result of f.
+func WithRW[T, R any](l *RWLockedValue[T], f func(*T) R) R {
+	l.lock()
+	defer l.unlock()
+	return f(&l.value)
+}
+
+// WithRLock calls f with a copy of the value under the read lock of l and returns the result of f.
+func WithRLock[T, R any](l *RWLockedValue[T], f func(T) R) R {
+	l.rlock()
+	defer l.runlock()
+	return f(l.value)
 }
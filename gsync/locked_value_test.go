@@ -1,6 +1,8 @@
 package gsync
 
 import (
+	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -77,3 +79,83 @@ func TestRWLockedValue(t *testing.T) {
 	assert.True(t, locked)
 	assert.Equal(t, 100, *val)
 }
+
+type lockedValueTestStruct struct {
+	count int
+	names map[string]int
+}
+
+func TestLockedValueUpdate(t *testing.T) {
+	t.Parallel()
+
+	lv := NewLockedValue(lockedValueTestStruct{names: map[string]int{}})
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			lv.Update(func(s *lockedValueTestStruct) {
+				s.count++
+				s.names[strconv.Itoa(i%10)]++
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	v := lv.Load()
+	assert.Equal(t, 100, v.count)
+	assert.Len(t, v.names, 10)
+
+	n := With(lv, func(s *lockedValueTestStruct) int {
+		s.count++
+		return s.count
+	})
+	assert.Equal(t, 101, n)
+
+	old := lv.Swap(lockedValueTestStruct{count: 1})
+	assert.Equal(t, 101, old.count)
+	assert.Equal(t, 1, lv.Load().count)
+}
+
+func TestRWLockedValueUpdate(t *testing.T) {
+	t.Parallel()
+
+	lv := NewRWLockedValue(lockedValueTestStruct{names: map[string]int{}})
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			lv.Update(func(s *lockedValueTestStruct) {
+				s.count++
+				s.names[strconv.Itoa(i%10)]++
+			})
+		}(i)
+		go func() {
+			defer wg.Done()
+			lv.RLockPtrCB(func(s *lockedValueTestStruct) {
+				_ = s.names["0"]
+			})
+		}()
+	}
+	wg.Wait()
+
+	v := lv.Load()
+	assert.Equal(t, 100, v.count)
+	assert.Len(t, v.names, 10)
+
+	n := WithRW(lv, func(s *lockedValueTestStruct) int {
+		s.count++
+		return s.count
+	})
+	assert.Equal(t, 101, n)
+
+	l := WithRLock(lv, func(s lockedValueTestStruct) int {
+		return len(s.names)
+	})
+	assert.Equal(t, 10, l)
+
+	old := lv.Swap(lockedValueTestStruct{count: 1})
+	assert.Equal(t, 101, old.count)
+	assert.Equal(t, 1, lv.Load().count)
+}
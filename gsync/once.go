@@ -0,0 +1,70 @@
+package gsync
+
+import "sync"
+
+// Lazy is a value computed by New on the first call of Get.
+// If New panics, Get panics with the same value on every call.
+type Lazy[T any] struct {
+	New  func() T
+	once sync.Once
+	get  func() T
+}
+
+// NewLazy returns a new Lazy computed by new.
+func NewLazy[T any](new func() T) *Lazy[T] {
+	return &Lazy[T]{New: new}
+}
+
+// Get returns the value, it is computed on the first call.
+func (l *Lazy[T]) Get() T {
+	l.once.Do(func() {
+		l.get = OnceValue(l.New)
+	})
+	return l.get()
+}
+
+// ResettableLazy is a value computed by New on demand, failures are not cached,
+// so Get retries New until it succeeds, and Reset forces the value to be computed again.
+type ResettableLazy[T any] struct {
+	New   func() (T, error)
+	mu    sync.RWMutex
+	done  bool
+	value T
+}
+
+// NewResettableLazy returns a new ResettableLazy computed by new.
+func NewResettableLazy[T any](new func() (T, error)) *ResettableLazy[T] {
+	return &ResettableLazy[T]{New: new}
+}
+
+// Get returns the value, it calls New if the value has not been computed successfully.
+// Concurrent callers wait for the same call of New.
+func (l *ResettableLazy[T]) Get() (T, error) {
+	l.mu.RLock()
+	if l.done {
+		defer l.mu.RUnlock()
+		return l.value, nil
+	}
+	l.mu.RUnlock()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.done {
+		return l.value, nil
+	}
+	v, err := l.New()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	l.value, l.done = v, true
+	return v, nil
+}
+
+// Reset drops the computed value, the next Get calls New again.
+func (l *ResettableLazy[T]) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var zero T
+	l.value, l.done = zero, false
+}
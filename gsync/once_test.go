@@ -0,0 +1,116 @@
+package gsync_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dashjay/gog/gsync"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnceValue(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	f := gsync.OnceValue(func() int {
+		return int(atomic.AddInt32(&calls, 1))
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, 1, f())
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	p := gsync.OnceValue(func() int {
+		panic("boom")
+	})
+	assert.PanicsWithValue(t, "boom", func() { p() })
+	assert.PanicsWithValue(t, "boom", func() { p() })
+}
+
+func TestOnceValues(t *testing.T) {
+	t.Parallel()
+
+	someErr := errors.New("some error")
+	calls := 0
+	f := gsync.OnceValues(func() (int, error) {
+		calls++
+		return calls, someErr
+	})
+	for i := 0; i < 3; i++ {
+		v, err := f()
+		assert.Equal(t, 1, v)
+		assert.Equal(t, someErr, err)
+	}
+
+	p := gsync.OnceValues(func() (int, error) {
+		panic("boom")
+	})
+	assert.PanicsWithValue(t, "boom", func() { p() })
+	assert.PanicsWithValue(t, "boom", func() { p() })
+}
+
+func TestLazy(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	l := gsync.NewLazy(func() []int {
+		calls++
+		return []int{1, 2, 3}
+	})
+	assert.Equal(t, []int{1, 2, 3}, l.Get())
+	assert.Equal(t, []int{1, 2, 3}, l.Get())
+	assert.Equal(t, 1, calls)
+
+	// the panic of New is raised on every call
+	p := gsync.Lazy[int]{New: func() int {
+		calls++
+		panic("boom")
+	}}
+	assert.PanicsWithValue(t, "boom", func() { p.Get() })
+	assert.PanicsWithValue(t, "boom", func() { p.Get() })
+	assert.Equal(t, 2, calls)
+}
+
+func TestResettableLazy(t *testing.T) {
+	t.Parallel()
+
+	someErr := errors.New("some error")
+	var calls int32
+	l := gsync.NewResettableLazy(func() (int, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if n == 1 {
+			return 0, someErr
+		}
+		return int(n), nil
+	})
+
+	// failures are not cached
+	_, err := l.Get()
+	assert.Equal(t, someErr, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := l.Get()
+			assert.Nil(t, err)
+			assert.Equal(t, 2, v)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	l.Reset()
+	v, err := l.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, v)
+}
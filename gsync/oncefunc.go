@@ -0,0 +1,70 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the third_party/go/LICENSE file.
+
+package gsync
+
+import "sync"
+
+// OnceValue returns a function that invokes f only once and returns the value returned by f.
+// If f panics, the returned function will panic with the same value on every call.
+//
+// It is the same as sync.OnceValue which is only available since go1.21.
+func OnceValue[T any](f func() T) func() T {
+	var (
+		once   sync.Once
+		valid  bool
+		p      any
+		result T
+	)
+	g := func() {
+		defer func() {
+			p = recover()
+			if !valid {
+				panic(p)
+			}
+		}()
+		result = f()
+		f = nil
+		valid = true
+	}
+	return func() T {
+		once.Do(g)
+		if !valid {
+			panic(p)
+		}
+		return result
+	}
+}
+
+// OnceValues returns a function that invokes f only once and returns the values returned by f.
+// If f panics, the returned function will panic with the same value on every call.
+//
+// It is the same as sync.OnceValues which is only available since go1.21.
+func OnceValues[T any](f func() (T, error)) func() (T, error) {
+	var (
+		once  sync.Once
+		valid bool
+		p     any
+		r1    T
+		r2    error
+	)
+	g := func() {
+		defer func() {
+			p = recover()
+			if !valid {
+				panic(p)
+			}
+		}()
+		r1, r2 = f()
+		f = nil
+		valid = true
+	}
+	return func() (T, error) {
+		once.Do(g)
+		if !valid {
+			panic(p)
+		}
+		return r1, r2
+	}
+}
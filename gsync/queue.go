@@ -0,0 +1,178 @@
+package gsync
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type queueCell[T any] struct {
+	seq uint64
+	val T
+}
+
+// queueSignal wakes goroutines blocked on a Queue without spinning.
+// Waiters register themselves before re-checking the queue, so a notify after a successful operation is never lost.
+type queueSignal struct {
+	waiters int32
+	ch      chan struct{}
+}
+
+func (s *queueSignal) notify() {
+	if atomic.LoadInt32(&s.waiters) == 0 {
+		return
+	}
+	select {
+	case s.ch <- struct{}{}:
+	default:
+	}
+}
+
+// Queue is a lock-free bounded multi-producer multi-consumer queue, based on Dmitry Vyukov's ring buffer with
+// per-cell sequence numbers. It is a typed alternative to a buffered channel for hot paths.
+// A Queue must be created by NewQueue.
+type Queue[T any] struct {
+	_        cacheLinePad
+	enqPos   uint64
+	_        cacheLinePad
+	deqPos   uint64
+	_        cacheLinePad
+	cells    []queueCell[T]
+	mask     uint64
+	notEmpty queueSignal
+	notFull  queueSignal
+}
+
+// NewQueue creates a new Queue, capacity is rounded up to a power of two and is at least 2.
+func NewQueue[T any](capacity int) *Queue[T] {
+	n := 2
+	for n < capacity {
+		n <<= 1
+	}
+	q := &Queue[T]{
+		cells:    make([]queueCell[T], n),
+		mask:     uint64(n - 1),
+		notEmpty: queueSignal{ch: make(chan struct{}, 1)},
+		notFull:  queueSignal{ch: make(chan struct{}, 1)},
+	}
+	for i := range q.cells {
+		q.cells[i].seq = uint64(i)
+	}
+	return q
+}
+
+// TryEnqueue adds v to the queue, it returns false without blocking if the queue is full.
+func (q *Queue[T]) TryEnqueue(v T) bool {
+	pos := atomic.LoadUint64(&q.enqPos)
+	for {
+		cell := &q.cells[pos&q.mask]
+		seq := atomic.LoadUint64(&cell.seq)
+		switch dif := int64(seq - pos); {
+		case dif == 0:
+			if atomic.CompareAndSwapUint64(&q.enqPos, pos, pos+1) {
+				cell.val = v
+				atomic.StoreUint64(&cell.seq, pos+1)
+				q.notEmpty.notify()
+				return true
+			}
+			pos = atomic.LoadUint64(&q.enqPos)
+		case dif < 0:
+			// the cell still holds the value of the previous lap
+			return false
+		default:
+			pos = atomic.LoadUint64(&q.enqPos)
+		}
+	}
+}
+
+// TryDequeue removes and returns the oldest value, ok is false without blocking if the queue is empty.
+func (q *Queue[T]) TryDequeue() (v T, ok bool) {
+	pos := atomic.LoadUint64(&q.deqPos)
+	for {
+		cell := &q.cells[pos&q.mask]
+		seq := atomic.LoadUint64(&cell.seq)
+		switch dif := int64(seq - (pos + 1)); {
+		case dif == 0:
+			if atomic.CompareAndSwapUint64(&q.deqPos, pos, pos+1) {
+				var zero T
+				v, cell.val = cell.val, zero
+				atomic.StoreUint64(&cell.seq, pos+q.mask+1)
+				q.notFull.notify()
+				return v, true
+			}
+			pos = atomic.LoadUint64(&q.deqPos)
+		case dif < 0:
+			// the cell has not been written in this lap
+			return v, false
+		default:
+			pos = atomic.LoadUint64(&q.deqPos)
+		}
+	}
+}
+
+// Enqueue adds v to the queue,
+// it blocks until there is room in the queue and returns ctx.Err() if ctx is done first.
+func (q *Queue[T]) Enqueue(ctx context.Context, v T) error {
+	if q.TryEnqueue(v) {
+		return nil
+	}
+	atomic.AddInt32(&q.notFull.waiters, 1)
+	defer atomic.AddInt32(&q.notFull.waiters, -1)
+	for {
+		if q.TryEnqueue(v) {
+			// pass on the wakeup in case it was meant for another waiter
+			if q.Len() < q.Cap() {
+				q.notFull.notify()
+			}
+			return nil
+		}
+		select {
+		case <-q.notFull.ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Dequeue removes and returns the oldest value,
+// it blocks until the queue is not empty and returns ctx.Err() if ctx is done first.
+func (q *Queue[T]) Dequeue(ctx context.Context) (T, error) {
+	if v, ok := q.TryDequeue(); ok {
+		return v, nil
+	}
+	atomic.AddInt32(&q.notEmpty.waiters, 1)
+	defer atomic.AddInt32(&q.notEmpty.waiters, -1)
+	for {
+		if v, ok := q.TryDequeue(); ok {
+			// pass on the wakeup in case it was meant for another waiter
+			if q.Len() > 0 {
+				q.notEmpty.notify()
+			}
+			return v, nil
+		}
+		select {
+		case <-q.notEmpty.ch:
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// Len returns the number of values in the queue, it is only a hint when there are concurrent operations.
+func (q *Queue[T]) Len() int {
+	deq := atomic.LoadUint64(&q.deqPos)
+	enq := atomic.LoadUint64(&q.enqPos)
+	n := int64(enq - deq)
+	if n < 0 {
+		return 0
+	}
+	if n > int64(q.mask+1) {
+		return q.Cap()
+	}
+	return int(n)
+}
+
+// Cap returns the capacity of the queue.
+func (q *Queue[T]) Cap() int {
+	return len(q.cells)
+}
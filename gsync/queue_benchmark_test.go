@@ -0,0 +1,80 @@
+package gsync_test
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/dashjay/gog/gsync"
+)
+
+const benchQueueSize = 1024
+
+func BenchmarkQueue(b *testing.B) {
+	b.Run("chan/try", func(b *testing.B) {
+		ch := make(chan int, benchQueueSize)
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				select {
+				case ch <- 1:
+				default:
+				}
+				select {
+				case <-ch:
+				default:
+				}
+			}
+		})
+	})
+
+	b.Run("Queue/try", func(b *testing.B) {
+		q := gsync.NewQueue[int](benchQueueSize)
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				q.TryEnqueue(1)
+				q.TryDequeue()
+			}
+		})
+	})
+
+	producers := runtime.GOMAXPROCS(0)
+	b.Run("chan/blocking", func(b *testing.B) {
+		ch := make(chan int, benchQueueSize)
+		benchmarkProducerConsumer(b, producers, func() { ch <- 1 }, func() { <-ch })
+	})
+
+	b.Run("Queue/blocking", func(b *testing.B) {
+		q := gsync.NewQueue[int](benchQueueSize)
+		ctx := context.Background()
+		benchmarkProducerConsumer(b, producers,
+			func() { _ = q.Enqueue(ctx, 1) },
+			func() { _, _ = q.Dequeue(ctx) })
+	})
+}
+
+// benchmarkProducerConsumer runs b.N enqueues split over n producers and b.N dequeues split over n consumers.
+func benchmarkProducerConsumer(b *testing.B, n int, enqueue, dequeue func()) {
+	var wg sync.WaitGroup
+	b.ResetTimer()
+	for i := 0; i < n; i++ {
+		count := b.N / n
+		if i < b.N%n {
+			count++
+		}
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < count; j++ {
+				enqueue()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < count; j++ {
+				dequeue()
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,106 @@
+package gsync_test
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dashjay/gog/gsync"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueue(t *testing.T) {
+	t.Parallel()
+
+	t.Run("try", func(t *testing.T) {
+		q := gsync.NewQueue[int](3)
+		assert.Equal(t, 4, q.Cap())
+		_, ok := q.TryDequeue()
+		assert.False(t, ok)
+
+		for i := 0; i < 4; i++ {
+			assert.True(t, q.TryEnqueue(i))
+		}
+		assert.False(t, q.TryEnqueue(4))
+		assert.Equal(t, 4, q.Len())
+
+		// wrap around several laps
+		for i := 0; i < 20; i++ {
+			v, ok := q.TryDequeue()
+			assert.True(t, ok)
+			assert.Equal(t, i, v)
+			assert.True(t, q.TryEnqueue(i+4))
+		}
+		assert.Equal(t, 4, q.Len())
+	})
+
+	t.Run("blocking", func(t *testing.T) {
+		q := gsync.NewQueue[int](2)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := q.Dequeue(ctx)
+		assert.Equal(t, context.DeadlineExceeded, err)
+
+		assert.Nil(t, q.Enqueue(context.Background(), 1))
+		assert.Nil(t, q.Enqueue(context.Background(), 2))
+		ctx2, cancel2 := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel2()
+		assert.Equal(t, context.DeadlineExceeded, q.Enqueue(ctx2, 3))
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			assert.Nil(t, q.Enqueue(context.Background(), 3))
+		}()
+		v, err := q.Dequeue(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 1, v)
+		<-done
+		assert.Equal(t, 2, q.Len())
+	})
+
+	t.Run("mpmc", func(t *testing.T) {
+		const producers, consumers, perProducer = 4, 4, 2000
+		q := gsync.NewQueue[int](8)
+		ctx := context.Background()
+
+		var pwg sync.WaitGroup
+		for p := 0; p < producers; p++ {
+			pwg.Add(1)
+			go func(p int) {
+				defer pwg.Done()
+				for i := 0; i < perProducer; i++ {
+					assert.Nil(t, q.Enqueue(ctx, p*perProducer+i))
+				}
+			}(p)
+		}
+
+		var mu sync.Mutex
+		var got []int
+		var cwg sync.WaitGroup
+		for c := 0; c < consumers; c++ {
+			cwg.Add(1)
+			go func() {
+				defer cwg.Done()
+				for i := 0; i < producers*perProducer/consumers; i++ {
+					v, err := q.Dequeue(ctx)
+					assert.Nil(t, err)
+					mu.Lock()
+					got = append(got, v)
+					mu.Unlock()
+				}
+			}()
+		}
+		pwg.Wait()
+		cwg.Wait()
+
+		sort.Ints(got)
+		assert.Len(t, got, producers*perProducer)
+		for i, v := range got {
+			assert.Equal(t, i, v)
+		}
+		assert.Equal(t, 0, q.Len())
+	})
+}
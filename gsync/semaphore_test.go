@@ -0,0 +1,100 @@
+package gsync_test
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dashjay/gog/gsync"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSemaphore(t *testing.T) {
+	t.Parallel()
+
+	t.Run("acquire and release", func(t *testing.T) {
+		s := gsync.NewSemaphore(10)
+		assert.Nil(t, s.Acquire(context.Background(), 6))
+		assert.True(t, s.TryAcquire(4))
+		assert.False(t, s.TryAcquire(1))
+		s.Release(10)
+		assert.True(t, s.TryAcquire(10))
+		s.Release(10)
+
+		assert.Panics(t, func() {
+			s.Release(1)
+		})
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		s := gsync.NewSemaphore(1)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.Equal(t, context.Canceled, s.Acquire(ctx, 1))
+
+		// larger than size
+		ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		assert.Equal(t, context.DeadlineExceeded, s.Acquire(ctx, 2))
+
+		assert.True(t, s.TryAcquire(1))
+		ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		assert.Equal(t, context.DeadlineExceeded, s.Acquire(ctx, 1))
+		s.Release(1)
+		// the canceled waiter leaves the semaphore unchanged
+		assert.True(t, s.TryAcquire(1))
+	})
+
+	t.Run("fifo", func(t *testing.T) {
+		s := gsync.NewSemaphore(10)
+		assert.True(t, s.TryAcquire(10))
+
+		var order []int64
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		// the large waiter comes first, so small ones must not overtake it
+		for i, n := range []int64{10, 1, 1} {
+			wg.Add(1)
+			go func(n int64) {
+				defer wg.Done()
+				assert.Nil(t, s.Acquire(context.Background(), n))
+				mu.Lock()
+				order = append(order, n)
+				mu.Unlock()
+				s.Release(n)
+			}(n)
+			// make sure the waiters are queued in order
+			for gsync.SemaphoreWaiters(s) != i+1 {
+				runtime.Gosched()
+			}
+		}
+		assert.False(t, s.TryAcquire(1))
+		s.Release(10)
+		wg.Wait()
+		assert.Equal(t, int64(10), order[0])
+		assert.Len(t, order, 3)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		const size = 3
+		s := gsync.NewSemaphore(size)
+		var running int32
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.Nil(t, s.Acquire(context.Background(), 1))
+				assert.LessOrEqual(t, atomic.AddInt32(&running, 1), int32(size))
+				atomic.AddInt32(&running, -1)
+				s.Release(1)
+			}()
+		}
+		wg.Wait()
+		assert.True(t, s.TryAcquire(size))
+	})
+}
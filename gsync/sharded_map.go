@@ -0,0 +1,291 @@
+package gsync
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dashjay/gog/giter"
+	"github.com/dashjay/gog/internal/constraints"
+)
+
+// DefaultShardCount is the number of shards used by NewShardedMap when shardCount <= 0.
+const DefaultShardCount = 32
+
+// Hasher hashes a key into a shard index source.
+type Hasher[K comparable] func(key K) uint64
+
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+// HashString is a built-in Hasher for string-like keys, using FNV-1a.
+func HashString[K ~string](key K) uint64 {
+	h := uint64(fnvOffset64)
+	for i := 0; i < len(key); i++ {
+		h ^= uint64(key[i])
+		h *= fnvPrime64
+	}
+	return h
+}
+
+// HashInteger is a built-in Hasher for integer keys, using the splitmix64 finalizer
+// so sequential keys are spread across shards.
+func HashInteger[K constraints.Integer](key K) uint64 {
+	h := uint64(key)
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
+
+// defaultHasher is used when no Hasher is provided.
+// It handles strings and integers directly and falls back to hashing the formatted key.
+func defaultHasher[K comparable](key K) uint64 {
+	switch k := any(key).(type) {
+	case string:
+		return HashString(k)
+	case int:
+		return HashInteger(k)
+	case int8:
+		return HashInteger(k)
+	case int16:
+		return HashInteger(k)
+	case int32:
+		return HashInteger(k)
+	case int64:
+		return HashInteger(k)
+	case uint:
+		return HashInteger(k)
+	case uint8:
+		return HashInteger(k)
+	case uint16:
+		return HashInteger(k)
+	case uint32:
+		return HashInteger(k)
+	case uint64:
+		return HashInteger(k)
+	case uintptr:
+		return HashInteger(k)
+	default:
+		return HashString(fmt.Sprintf("%#v", key))
+	}
+}
+
+type mapEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+type mapShard[K comparable, V any] struct {
+	mu sync.RWMutex
+	m  map[K]V
+}
+
+// ShardedMap is a concurrent map split into several shards, each protected by its own RWMutex.
+// Compared with SyncMap, it performs better for write-heavy workloads and provides an O(1) Len.
+type ShardedMap[K comparable, V any] struct {
+	length int64
+	shards []mapShard[K, V]
+	mask   uint64
+	hasher Hasher[K]
+}
+
+// NewShardedMap creates a new ShardedMap.
+// shardCount is rounded up to a power of two, DefaultShardCount is used when shardCount <= 0.
+// hasher is used to pick the shard for a key, a default hasher is used when hasher is nil,
+// the default hasher is fast for strings and integers but slow for other key types.
+func NewShardedMap[K comparable, V any](shardCount int, hasher Hasher[K]) *ShardedMap[K, V] {
+	if shardCount <= 0 {
+		shardCount = DefaultShardCount
+	}
+	n := 1
+	for n < shardCount {
+		n <<= 1
+	}
+	if hasher == nil {
+		hasher = defaultHasher[K]
+	}
+	s := &ShardedMap[K, V]{
+		shards: make([]mapShard[K, V], n),
+		mask:   uint64(n - 1),
+		hasher: hasher,
+	}
+	for i := range s.shards {
+		s.shards[i].m = make(map[K]V)
+	}
+	return s
+}
+
+func (s *ShardedMap[K, V]) shard(key K) *mapShard[K, V] {
+	return &s.shards[s.hasher(key)&s.mask]
+}
+
+// Load returns the value stored in the map for a key.
+func (s *ShardedMap[K, V]) Load(key K) (value V, ok bool) {
+	sh := s.shard(key)
+	sh.mu.RLock()
+	value, ok = sh.m[key]
+	sh.mu.RUnlock()
+	return
+}
+
+// Store sets the value for a key.
+func (s *ShardedMap[K, V]) Store(key K, value V) {
+	sh := s.shard(key)
+	sh.mu.Lock()
+	if _, exists := sh.m[key]; !exists {
+		atomic.AddInt64(&s.length, 1)
+	}
+	sh.m[key] = value
+	sh.mu.Unlock()
+}
+
+// LoadOrStore returns the existing value for the key if present.
+// Otherwise, it stores and returns the given value.
+// The loaded result is true if the value was loaded, false if stored.
+func (s *ShardedMap[K, V]) LoadOrStore(key K, value V) (actual V, loaded bool) {
+	sh := s.shard(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if actual, loaded = sh.m[key]; loaded {
+		return
+	}
+	sh.m[key] = value
+	atomic.AddInt64(&s.length, 1)
+	return value, false
+}
+
+// LoadAndDelete deletes the value for a key, returning the previous value if any.
+// The loaded result reports whether the key was present.
+func (s *ShardedMap[K, V]) LoadAndDelete(key K) (value V, loaded bool) {
+	sh := s.shard(key)
+	sh.mu.Lock()
+	value, loaded = sh.m[key]
+	if loaded {
+		delete(sh.m, key)
+		atomic.AddInt64(&s.length, -1)
+	}
+	sh.mu.Unlock()
+	return
+}
+
+// Delete deletes the value for a key.
+func (s *ShardedMap[K, V]) Delete(key K) {
+	s.LoadAndDelete(key)
+}
+
+// Swap swaps the value for a key and returns the previous value if any.
+// The loaded result reports whether the key was present.
+func (s *ShardedMap[K, V]) Swap(key K, value V) (previous V, loaded bool) {
+	sh := s.shard(key)
+	sh.mu.Lock()
+	previous, loaded = sh.m[key]
+	if !loaded {
+		atomic.AddInt64(&s.length, 1)
+	}
+	sh.m[key] = value
+	sh.mu.Unlock()
+	return
+}
+
+// StoreMany sets all key-value pairs in kvs, each shard is locked only once.
+func (s *ShardedMap[K, V]) StoreMany(kvs map[K]V) {
+	groups := make(map[uint64][]K)
+	for k := range kvs {
+		idx := s.hasher(k) & s.mask
+		groups[idx] = append(groups[idx], k)
+	}
+	for idx, keys := range groups {
+		sh := &s.shards[idx]
+		sh.mu.Lock()
+		for _, k := range keys {
+			if _, exists := sh.m[k]; !exists {
+				atomic.AddInt64(&s.length, 1)
+			}
+			sh.m[k] = kvs[k]
+		}
+		sh.mu.Unlock()
+	}
+}
+
+// DeleteMany deletes all given keys, each shard is locked only once.
+func (s *ShardedMap[K, V]) DeleteMany(keys ...K) {
+	groups := make(map[uint64][]K)
+	for _, k := range keys {
+		idx := s.hasher(k) & s.mask
+		groups[idx] = append(groups[idx], k)
+	}
+	for idx, ks := range groups {
+		sh := &s.shards[idx]
+		sh.mu.Lock()
+		for _, k := range ks {
+			if _, exists := sh.m[k]; exists {
+				delete(sh.m, k)
+				atomic.AddInt64(&s.length, -1)
+			}
+		}
+		sh.mu.Unlock()
+	}
+}
+
+// Range calls f sequentially for each key and value present in the map.
+// If f returns false, range stops the iteration.
+//
+// Like sync.Map.Range, Range does not correspond to a consistent snapshot of the whole map,
+// each shard is copied under its read lock and f is called without holding any lock,
+// so it is safe to modify the map in f.
+func (s *ShardedMap[K, V]) Range(f func(key K, value V) bool) {
+	var buf []mapEntry[K, V]
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.RLock()
+		buf = buf[:0]
+		for k, v := range sh.m {
+			buf = append(buf, mapEntry[K, V]{key: k, value: v})
+		}
+		sh.mu.RUnlock()
+		for j := range buf {
+			if !f(buf[j].key, buf[j].value) {
+				return
+			}
+		}
+	}
+}
+
+// All returns a Seq2 over all key-value pairs in the map, see Range for the consistency guarantee.
+func (s *ShardedMap[K, V]) All() giter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		s.Range(yield)
+	}
+}
+
+// Len returns the number of elements in the map.
+// The complexity is O(1).
+func (s *ShardedMap[K, V]) Len() int {
+	return int(atomic.LoadInt64(&s.length))
+}
+
+// Clear deletes all the entries, resulting in an empty map.
+func (s *ShardedMap[K, V]) Clear() {
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.Lock()
+		atomic.AddInt64(&s.length, -int64(len(sh.m)))
+		sh.m = make(map[K]V)
+		sh.mu.Unlock()
+	}
+}
+
+// ToMap returns a copy of the map as a regular map.
+func (s *ShardedMap[K, V]) ToMap() map[K]V {
+	out := make(map[K]V, s.Len())
+	s.Range(func(key K, value V) bool {
+		out[key] = value
+		return true
+	})
+	return out
+}
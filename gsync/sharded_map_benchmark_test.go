@@ -0,0 +1,58 @@
+package gsync_test
+
+import (
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dashjay/gog/gsync"
+)
+
+type benchMap interface {
+	Load(key string) (int, bool)
+	Store(key string, value int)
+}
+
+func benchmarkMapMix(b *testing.B, m benchMap, writePercent int) {
+	const keyCount = 1 << 12
+	keys := make([]string, keyCount)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		m.Store(keys[i], i)
+	}
+	var seed int64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := int(atomic.AddInt64(&seed, 1)) * 7919
+		for pb.Next() {
+			i++
+			key := keys[i&(keyCount-1)]
+			if i%100 < writePercent {
+				m.Store(key, i)
+			} else {
+				m.Load(key)
+			}
+		}
+	})
+}
+
+func BenchmarkShardedMap(b *testing.B) {
+	mixes := []struct {
+		name         string
+		writePercent int
+	}{
+		{name: "read heavy", writePercent: 10},
+		{name: "balanced", writePercent: 50},
+		{name: "write heavy", writePercent: 90},
+	}
+	for _, mix := range mixes {
+		b.Run(mix.name, func(b *testing.B) {
+			b.Run("SyncMap", func(b *testing.B) {
+				benchmarkMapMix(b, gsync.NewSyncMap[string, int](), mix.writePercent)
+			})
+			b.Run("ShardedMap", func(b *testing.B) {
+				benchmarkMapMix(b, gsync.NewShardedMap[string, int](0, gsync.HashString[string]), mix.writePercent)
+			})
+		})
+	}
+}
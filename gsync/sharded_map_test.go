@@ -0,0 +1,155 @@
+package gsync_test
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/dashjay/gog/gsync"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardedMap(t *testing.T) {
+	t.Parallel()
+
+	t.Run("simple store and load", func(t *testing.T) {
+		m := gsync.NewShardedMap[string, int](0, gsync.HashString[string])
+		v, exists := m.Load("1")
+		assert.False(t, exists)
+		assert.Equal(t, 0, v)
+		m.Store("1", 1)
+		v, exists = m.Load("1")
+		assert.True(t, exists)
+		assert.Equal(t, 1, v)
+		m.Store("1", 2)
+		assert.Equal(t, 1, m.Len())
+	})
+
+	t.Run("simple load or store", func(t *testing.T) {
+		m := gsync.NewShardedMap[int, int](3, gsync.HashInteger[int])
+		v, loaded := m.LoadOrStore(1, 1)
+		assert.False(t, loaded)
+		assert.Equal(t, 1, v)
+		v, loaded = m.LoadOrStore(1, 2)
+		assert.True(t, loaded)
+		assert.Equal(t, 1, v)
+		assert.Equal(t, 1, m.Len())
+	})
+
+	t.Run("simple load and delete", func(t *testing.T) {
+		m := gsync.NewShardedMap[string, int](0, nil)
+		v, loaded := m.LoadAndDelete("1")
+		assert.False(t, loaded)
+		assert.Equal(t, 0, v)
+		m.Store("1", 1)
+		v, loaded = m.LoadAndDelete("1")
+		assert.True(t, loaded)
+		assert.Equal(t, 1, v)
+		assert.Equal(t, 0, m.Len())
+
+		m.Store("1", 1)
+		m.Delete("1")
+		m.Delete("1")
+		_, exists := m.Load("1")
+		assert.False(t, exists)
+		assert.Equal(t, 0, m.Len())
+	})
+
+	t.Run("simple swap", func(t *testing.T) {
+		m := gsync.NewShardedMap[string, int](0, nil)
+		prev, loaded := m.Swap("1", 1)
+		assert.False(t, loaded)
+		assert.Equal(t, 0, prev)
+		prev, loaded = m.Swap("1", 2)
+		assert.True(t, loaded)
+		assert.Equal(t, 1, prev)
+		assert.Equal(t, 1, m.Len())
+	})
+
+	t.Run("store many and delete many", func(t *testing.T) {
+		m := gsync.NewShardedMap[int, string](4, nil)
+		kvs := make(map[int]string)
+		for i := 0; i < 100; i++ {
+			kvs[i] = strconv.Itoa(i)
+		}
+		m.Store(0, "0")
+		m.StoreMany(kvs)
+		assert.Equal(t, 100, m.Len())
+		assert.Equal(t, kvs, m.ToMap())
+
+		m.DeleteMany(0, 1, 2, 2, 1000)
+		assert.Equal(t, 97, m.Len())
+		_, exists := m.Load(2)
+		assert.False(t, exists)
+	})
+
+	t.Run("default hasher for other key types", func(t *testing.T) {
+		type key struct {
+			a int
+			b string
+		}
+		m := gsync.NewShardedMap[key, int](0, nil)
+		m.Store(key{a: 1, b: "1"}, 1)
+		v, exists := m.Load(key{a: 1, b: "1"})
+		assert.True(t, exists)
+		assert.Equal(t, 1, v)
+	})
+
+	t.Run("simple range_all_len_clear", func(t *testing.T) {
+		m := gsync.NewShardedMap[string, int](0, nil)
+		const count = 100
+		for i := 0; i < count; i++ {
+			m.Store(strconv.Itoa(i), i)
+		}
+		seen := 0
+		m.Range(func(key string, value int) bool {
+			assert.Equal(t, strconv.Itoa(value), key)
+			seen++
+			return true
+		})
+		assert.Equal(t, count, seen)
+		assert.Equal(t, count, m.Len())
+
+		seen = 0
+		m.All()(func(key string, value int) bool {
+			seen++
+			return seen < 10
+		})
+		assert.Equal(t, 10, seen)
+
+		// modify the map while ranging
+		m.Range(func(key string, value int) bool {
+			m.Delete(key)
+			return true
+		})
+		assert.Equal(t, 0, m.Len())
+
+		for i := 0; i < count; i++ {
+			m.Store(strconv.Itoa(i), i)
+		}
+		m.Clear()
+		assert.Equal(t, 0, m.Len())
+		assert.Len(t, m.ToMap(), 0)
+	})
+
+	t.Run("concurrent simple test", func(t *testing.T) {
+		m := gsync.NewShardedMap[string, int](0, nil)
+		var wg sync.WaitGroup
+		const count = 10_000
+		concurrency := 10
+		ch := make(chan struct{}, concurrency)
+		for i := 0; i < count; i++ {
+			ch <- struct{}{}
+			wg.Add(1)
+			go func(idx int) {
+				defer wg.Done()
+				<-ch
+				m.Store(strconv.Itoa(idx), idx)
+				m.Store(strconv.Itoa(idx), idx)
+			}(i)
+		}
+		wg.Wait()
+		assert.Equal(t, count, m.Len())
+		assert.Len(t, m.ToMap(), count)
+	})
+}
@@ -0,0 +1,196 @@
+package gsync
+
+import (
+	"sync"
+	"time"
+)
+
+// SingleFlightResult holds the results of SingleFlight.Do, so they can be passed on a channel.
+type SingleFlightResult[V any] struct {
+	Val    V
+	Err    error
+	Shared bool
+}
+
+type singleFlightCall[V any] struct {
+	done  chan struct{}
+	val   V
+	err   error
+	dups  int
+	chans []chan<- SingleFlightResult[V]
+}
+
+// minSingleFlightSweep is the minimum cache size at which expired results are swept.
+const minSingleFlightSweep = 16
+
+type singleFlightCache[V any] struct {
+	val      V
+	expireAt time.Time
+}
+
+// SingleFlight provides a duplicate function call suppression mechanism,
+// it is the type-safe version of golang.org/x/sync/singleflight.
+//
+// The zero value is ready to use and does not cache results.
+type SingleFlight[K comparable, V any] struct {
+	mu    sync.Mutex
+	calls map[K]*singleFlightCall[V]
+	cache map[K]*singleFlightCache[V]
+	// sweepAt is the cache size at which expired results are swept,
+	// it doubles the size after a sweep so that the cost of sweeps is amortized.
+	sweepAt int
+	ttl     time.Duration
+	clock   Clock
+}
+
+// NewSingleFlight returns a new SingleFlight.
+// If ttl > 0, successful results are cached for ttl and returned as shared results without calling fn again,
+// expired results are swept when new results are cached, so keys which are never looked up again do not pile up.
+func NewSingleFlight[K comparable, V any](ttl time.Duration) *SingleFlight[K, V] {
+	return &SingleFlight[K, V]{ttl: ttl, clock: RealClock}
+}
+
+// loadCachedLocked returns the cached result of key if it has not expired, g.mu must be held.
+func (g *SingleFlight[K, V]) loadCachedLocked(key K) (val V, ok bool) {
+	if g.ttl <= 0 {
+		return
+	}
+	c, exists := g.cache[key]
+	if !exists {
+		return
+	}
+	if !g.clock.Now().Before(c.expireAt) {
+		delete(g.cache, key)
+		return
+	}
+	return c.val, true
+}
+
+// storeCachedLocked caches val for key, sweeping the expired results when the cache grows, g.mu must be held.
+func (g *SingleFlight[K, V]) storeCachedLocked(key K, val V) {
+	if g.cache == nil {
+		g.cache = make(map[K]*singleFlightCache[V])
+	}
+	now := g.clock.Now()
+	if len(g.cache) >= g.sweepAt {
+		for k, c := range g.cache {
+			if !now.Before(c.expireAt) {
+				delete(g.cache, k)
+			}
+		}
+		g.sweepAt = 2 * len(g.cache)
+		if g.sweepAt < minSingleFlightSweep {
+			g.sweepAt = minSingleFlightSweep
+		}
+	}
+	g.cache[key] = &singleFlightCache[V]{val: val, expireAt: now.Add(g.ttl)}
+}
+
+// Do executes and returns the results of fn, making sure that only one execution is in-flight for a given key at a time.
+// If a duplicate comes in, the duplicate caller waits for the original to complete and receives the same results.
+// The return value shared reports whether v was given to multiple callers.
+//
+// If fn panics, the panic is propagated to all callers waiting in Do as a *PanicError.
+func (g *SingleFlight[K, V]) Do(key K, fn func() (V, error)) (v V, err error, shared bool) {
+	g.mu.Lock()
+	if val, ok := g.loadCachedLocked(key); ok {
+		g.mu.Unlock()
+		return val, nil, true
+	}
+	if g.calls == nil {
+		g.calls = make(map[K]*singleFlightCall[V])
+	}
+	if c, ok := g.calls[key]; ok {
+		c.dups++
+		g.mu.Unlock()
+		<-c.done
+		if pe, ok := c.err.(*PanicError); ok {
+			panic(pe)
+		}
+		return c.val, c.err, true
+	}
+	c := &singleFlightCall[V]{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	g.doCall(c, key, fn, true)
+	return c.val, c.err, c.dups > 0
+}
+
+// DoChan is like Do but returns a channel that will receive the results when they are ready.
+//
+// If fn panics, the receivers get a *PanicError as the Err of the result.
+func (g *SingleFlight[K, V]) DoChan(key K, fn func() (V, error)) <-chan SingleFlightResult[V] {
+	ch := make(chan SingleFlightResult[V], 1)
+	g.mu.Lock()
+	if val, ok := g.loadCachedLocked(key); ok {
+		g.mu.Unlock()
+		ch <- SingleFlightResult[V]{Val: val, Shared: true}
+		return ch
+	}
+	if g.calls == nil {
+		g.calls = make(map[K]*singleFlightCall[V])
+	}
+	if c, ok := g.calls[key]; ok {
+		c.dups++
+		c.chans = append(c.chans, ch)
+		g.mu.Unlock()
+		return ch
+	}
+	c := &singleFlightCall[V]{done: make(chan struct{}), chans: []chan<- SingleFlightResult[V]{ch}}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	go g.doCall(c, key, fn, false)
+	return ch
+}
+
+// doCall handles the single call for a key, rethrow reports whether a panic in fn should be rethrown.
+func (g *SingleFlight[K, V]) doCall(c *singleFlightCall[V], key K, fn func() (V, error), rethrow bool) {
+	normalReturn := false
+	defer func() {
+		if !normalReturn && c.err == nil {
+			c.err = errGoexit
+		}
+		g.mu.Lock()
+		// the result is not cached if the key has been forgotten during the call
+		if g.calls[key] == c {
+			delete(g.calls, key)
+			if c.err == nil && g.ttl > 0 {
+				g.storeCachedLocked(key, c.val)
+			}
+		}
+		close(c.done)
+		for _, ch := range c.chans {
+			ch <- SingleFlightResult[V]{Val: c.val, Err: c.err, Shared: c.dups > 0}
+		}
+		g.mu.Unlock()
+
+		if pe, ok := c.err.(*PanicError); ok && rethrow {
+			panic(pe)
+		}
+	}()
+
+	func() {
+		defer func() {
+			if !normalReturn {
+				if r := recover(); r != nil {
+					c.err = newPanicError(r)
+				}
+			}
+		}()
+		c.val, c.err = fn()
+		normalReturn = true
+	}()
+	// fn panicked and was recovered, the deferred function above reports it
+	normalReturn = true
+}
+
+// Forget tells the SingleFlight to forget about a key, both the in-flight call and the cached result.
+// Future calls to Do for this key will call the function rather than waiting for an earlier call to complete.
+func (g *SingleFlight[K, V]) Forget(key K) {
+	g.mu.Lock()
+	delete(g.calls, key)
+	delete(g.cache, key)
+	g.mu.Unlock()
+}
@@ -0,0 +1,245 @@
+package gsync
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSingleFlight(t *testing.T) {
+	t.Parallel()
+
+	t.Run("simple do", func(t *testing.T) {
+		var g SingleFlight[string, int]
+		v, err, shared := g.Do("key", func() (int, error) {
+			return 1, nil
+		})
+		assert.Equal(t, 1, v)
+		assert.Nil(t, err)
+		assert.False(t, shared)
+
+		someErr := errors.New("some error")
+		_, err, _ = g.Do("key", func() (int, error) {
+			return 0, someErr
+		})
+		assert.Equal(t, someErr, err)
+	})
+
+	t.Run("duplicate suppression", func(t *testing.T) {
+		var g SingleFlight[string, int]
+		var calls int32
+		block := make(chan struct{})
+		fn := func() (int, error) {
+			atomic.AddInt32(&calls, 1)
+			<-block
+			return 1, nil
+		}
+
+		const n = 10
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				v, err, shared := g.Do("key", fn)
+				assert.Equal(t, 1, v)
+				assert.Nil(t, err)
+				assert.True(t, shared)
+			}()
+		}
+		// wait until all the callers join the call
+		for {
+			g.mu.Lock()
+			c := g.calls["key"]
+			joined := c != nil && c.dups == n-1
+			g.mu.Unlock()
+			if joined {
+				break
+			}
+			runtime.Gosched()
+		}
+		ch := g.DoChan("key", fn)
+		close(block)
+		wg.Wait()
+		res := <-ch
+		assert.Equal(t, SingleFlightResult[int]{Val: 1, Shared: true}, res)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("do chan", func(t *testing.T) {
+		var g SingleFlight[string, int]
+		res := <-g.DoChan("key", func() (int, error) {
+			return 2, nil
+		})
+		assert.Equal(t, SingleFlightResult[int]{Val: 2}, res)
+
+		res = <-g.DoChan("key", func() (int, error) {
+			panic("boom")
+		})
+		var pe *PanicError
+		assert.True(t, errors.As(res.Err, &pe))
+		assert.Equal(t, "boom", pe.Value)
+	})
+
+	t.Run("panic propagated to all waiters", func(t *testing.T) {
+		var g SingleFlight[string, int]
+		block := make(chan struct{})
+		fn := func() (int, error) {
+			<-block
+			panic("boom")
+		}
+		const n = 5
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() {
+					r := recover()
+					pe, ok := r.(*PanicError)
+					assert.True(t, ok)
+					assert.Equal(t, "boom", pe.Value)
+				}()
+				g.Do("key", fn)
+			}()
+		}
+		for {
+			g.mu.Lock()
+			c := g.calls["key"]
+			joined := c != nil && c.dups == n-1
+			g.mu.Unlock()
+			if joined {
+				break
+			}
+			runtime.Gosched()
+		}
+		close(block)
+		wg.Wait()
+	})
+
+	t.Run("goexit", func(t *testing.T) {
+		var g SingleFlight[string, int]
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			g.Do("key", func() (int, error) {
+				runtime.Goexit()
+				return 0, nil
+			})
+		}()
+		<-done
+		res := <-g.DoChan("key", func() (int, error) {
+			runtime.Goexit()
+			return 0, nil
+		})
+		assert.Equal(t, errGoexit, res.Err)
+	})
+
+	t.Run("forget", func(t *testing.T) {
+		var g SingleFlight[string, int]
+		block := make(chan struct{})
+		ch := g.DoChan("key", func() (int, error) {
+			<-block
+			return 1, nil
+		})
+		g.Forget("key")
+		v, _, shared := g.Do("key", func() (int, error) {
+			return 2, nil
+		})
+		assert.Equal(t, 2, v)
+		assert.False(t, shared)
+		close(block)
+		assert.Equal(t, 1, (<-ch).Val)
+	})
+
+	t.Run("cache ttl", func(t *testing.T) {
+		clock := NewManualClock(time.Now())
+		g := NewSingleFlight[string, int](time.Second)
+		g.clock = clock
+		var calls int32
+		fn := func() (int, error) {
+			return int(atomic.AddInt32(&calls, 1)), nil
+		}
+
+		v, _, shared := g.Do("key", fn)
+		assert.Equal(t, 1, v)
+		assert.False(t, shared)
+
+		clock.Advance(time.Second / 2)
+		v, _, shared = g.Do("key", fn)
+		assert.Equal(t, 1, v)
+		assert.True(t, shared)
+		res := <-g.DoChan("key", fn)
+		assert.Equal(t, SingleFlightResult[int]{Val: 1, Shared: true}, res)
+
+		clock.Advance(time.Second / 2)
+		v, _, _ = g.Do("key", fn)
+		assert.Equal(t, 2, v)
+
+		g.Forget("key")
+		v, _, _ = g.Do("key", fn)
+		assert.Equal(t, 3, v)
+
+		// errors are not cached
+		_, err, _ := g.Do("err", func() (int, error) {
+			return 0, errors.New("some error")
+		})
+		assert.NotNil(t, err)
+		v, err, _ = g.Do("err", fn)
+		assert.Nil(t, err)
+		assert.Equal(t, 4, v)
+	})
+
+	t.Run("forget during call", func(t *testing.T) {
+		g := NewSingleFlight[string, int](time.Hour)
+		release := make(chan struct{})
+		done := make(chan int)
+		go func() {
+			v, _, _ := g.Do("key", func() (int, error) {
+				<-release
+				return 1, nil
+			})
+			done <- v
+		}()
+		for {
+			g.mu.Lock()
+			n := len(g.calls)
+			g.mu.Unlock()
+			if n == 1 {
+				break
+			}
+			runtime.Gosched()
+		}
+		g.Forget("key")
+		close(release)
+		assert.Equal(t, 1, <-done)
+
+		v, _, shared := g.Do("key", func() (int, error) {
+			return 2, nil
+		})
+		assert.Equal(t, 2, v)
+		assert.False(t, shared)
+	})
+
+	t.Run("expired results swept", func(t *testing.T) {
+		clock := NewManualClock(time.Now())
+		g := NewSingleFlight[int, int](time.Second)
+		g.clock = clock
+		fn := func() (int, error) {
+			return 1, nil
+		}
+		for i := 0; i < minSingleFlightSweep; i++ {
+			g.Do(i, fn)
+		}
+		assert.Len(t, g.cache, minSingleFlightSweep)
+
+		clock.Advance(time.Second)
+		g.Do(-1, fn)
+		assert.Len(t, g.cache, 1)
+	})
+}
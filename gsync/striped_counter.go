@@ -0,0 +1,96 @@
+package gsync
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// cacheLinePad prevents false sharing between adjacent cells.
+type cacheLinePad struct {
+	_ [64]byte
+}
+
+type counterCell struct {
+	v int64
+	_ [56]byte
+}
+
+type stripeHint struct {
+	idx uint32
+}
+
+// StripedCounter is a counter for high-contention increments, like LongAdder in Java.
+// The count is spread over several cells, so concurrent Add rarely contend on the same cache line,
+// in exchange Load has to sum all cells and is not an atomic snapshot when there are concurrent updates.
+//
+// The zero value is ready to use, the cells are allocated on first use.
+type StripedCounter struct {
+	_     cacheLinePad
+	once  sync.Once
+	cells []counterCell
+	mask  uint32
+	next  uint32
+	// hints is a per-P cache of cell indexes, sync.Pool keeps a private object for each P.
+	hints sync.Pool
+}
+
+// NewStripedCounter creates a new StripedCounter with cells for GOMAXPROCS.
+func NewStripedCounter() *StripedCounter {
+	c := &StripedCounter{}
+	c.init()
+	return c
+}
+
+// init allocates the cells for GOMAXPROCS on first use.
+func (c *StripedCounter) init() {
+	c.once.Do(func() {
+		n := 1
+		for n < runtime.GOMAXPROCS(0) {
+			n <<= 1
+		}
+		c.cells = make([]counterCell, n)
+		c.mask = uint32(n - 1)
+		c.hints.New = func() any {
+			return &stripeHint{idx: atomic.AddUint32(&c.next, 1) - 1}
+		}
+	})
+}
+
+// Add adds delta to the counter.
+func (c *StripedCounter) Add(delta int64) {
+	c.init()
+	hint := c.hints.Get().(*stripeHint)
+	atomic.AddInt64(&c.cells[hint.idx&c.mask].v, delta)
+	c.hints.Put(hint)
+}
+
+// Inc adds 1 to the counter.
+func (c *StripedCounter) Inc() {
+	c.Add(1)
+}
+
+// Dec adds -1 to the counter.
+func (c *StripedCounter) Dec() {
+	c.Add(-1)
+}
+
+// Load returns the sum of all cells.
+func (c *StripedCounter) Load() int64 {
+	c.init()
+	var sum int64
+	for i := range c.cells {
+		sum += atomic.LoadInt64(&c.cells[i].v)
+	}
+	return sum
+}
+
+// Reset sets the counter to zero and returns the sum before resetting.
+func (c *StripedCounter) Reset() int64 {
+	c.init()
+	var sum int64
+	for i := range c.cells {
+		sum += atomic.SwapInt64(&c.cells[i].v, 0)
+	}
+	return sum
+}
@@ -0,0 +1,28 @@
+package gsync_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/dashjay/gog/gsync"
+)
+
+func BenchmarkStripedCounter(b *testing.B) {
+	b.Run("atomic", func(b *testing.B) {
+		var n int64
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				atomic.AddInt64(&n, 1)
+			}
+		})
+	})
+
+	b.Run("StripedCounter", func(b *testing.B) {
+		c := gsync.NewStripedCounter()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				c.Inc()
+			}
+		})
+	})
+}
@@ -2,10 +2,13 @@ package gsync
 
 import "sync"
 
+// SyncPool is a wrapper for sync.Pool.
 type SyncPool[T any] struct {
-	New  func() T
-	pool sync.Pool
-	once sync.Once
+	New func() T
+	// Reset is optional, it is applied on Put so that the next Get never sees data left by the previous user.
+	Reset func(T)
+	pool  sync.Pool
+	once  sync.Once
 }
 
 // NewSyncPool creates a new SyncPool with specified init function
@@ -32,8 +35,11 @@ func (s *SyncPool[T]) Get() T {
 	return s.pool.Get().(T)
 }
 
-// Put wraps sync.Pool.Put.
+// Put wraps sync.Pool.Put, x is reset by Reset first if it is provided.
 func (s *SyncPool[T]) Put(x T) {
 	s.init()
+	if s.Reset != nil {
+		s.Reset(x)
+	}
 	s.pool.Put(x)
 }
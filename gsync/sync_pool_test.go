@@ -1,9 +1,11 @@
 package gsync_test
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/dashjay/gog/gsync"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestSyncPool(t *testing.T) {
@@ -16,3 +18,19 @@ func TestSyncPool(t *testing.T) {
 		p.Put(v)
 	}
 }
+
+func TestSyncPoolReset(t *testing.T) {
+	p := gsync.NewSyncPool[*bytes.Buffer](func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	})
+	p.Reset = func(b *bytes.Buffer) {
+		b.Reset()
+	}
+
+	for i := 0; i < 1000; i++ {
+		b := p.Get()
+		assert.Equal(t, 0, b.Len())
+		b.WriteString("secret")
+		p.Put(b)
+	}
+}
@@ -0,0 +1,137 @@
+package gsync
+
+import (
+	"context"
+	"sync"
+)
+
+// Versioned is a value with the version it was stored at.
+type Versioned[T any] struct {
+	Value   T
+	Version uint64
+}
+
+// Watched is a value protected by a RWMutex with a monotonically increasing version,
+// goroutines can wait for changes of the value instead of polling it.
+//
+// The initial value has version 0, every modification increases the version by 1.
+// The zero value is ready to use, it holds the zero value of T.
+type Watched[T any] struct {
+	mu      sync.RWMutex
+	value   T
+	version uint64
+	// changed is closed on the next modification to wake up all waiters,
+	// it is created by the first waiter after a modification.
+	changed chan struct{}
+	_       noCopy
+}
+
+// NewWatched returns a new Watched with value at version 0.
+func NewWatched[T any](value T) *Watched[T] {
+	return &Watched[T]{value: value}
+}
+
+// Load returns a copy of the value and its version.
+func (w *Watched[T]) Load() (value T, version uint64) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.value, w.version
+}
+
+// Version returns the current version.
+func (w *Watched[T]) Version() uint64 {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.version
+}
+
+// commitLocked bumps the version and wakes up all waiters, w.mu must be held.
+func (w *Watched[T]) commitLocked() uint64 {
+	w.version++
+	if w.changed != nil {
+		close(w.changed)
+		w.changed = nil
+	}
+	return w.version
+}
+
+// Store replaces the value and returns the new version.
+func (w *Watched[T]) Store(value T) (version uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.value = value
+	return w.commitLocked()
+}
+
+// Update calls f with a pointer to the value under the lock and returns the new version.
+func (w *Watched[T]) Update(f func(*T)) (version uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f(&w.value)
+	return w.commitLocked()
+}
+
+// CompareAndSet replaces the value only if the current version is still version,
+// it returns the new version and true if the value is replaced, the current version and false if not.
+func (w *Watched[T]) CompareAndSet(version uint64, value T) (newVersion uint64, swapped bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.version != version {
+		return w.version, false
+	}
+	w.value = value
+	return w.commitLocked(), true
+}
+
+// WaitChange blocks until the version is newer than sinceVersion and returns the value with its version,
+// it returns immediately if the version is already newer, and returns ctx.Err() if ctx is done first.
+func (w *Watched[T]) WaitChange(ctx context.Context, sinceVersion uint64) (value T, version uint64, err error) {
+	for {
+		w.mu.RLock()
+		value, version, changed := w.value, w.version, w.changed
+		w.mu.RUnlock()
+		if version > sinceVersion {
+			return value, version, nil
+		}
+		if changed == nil {
+			w.mu.Lock()
+			if w.changed == nil {
+				w.changed = make(chan struct{})
+			}
+			w.mu.Unlock()
+			continue
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			var zero T
+			return zero, version, ctx.Err()
+		}
+	}
+}
+
+// Subscribe returns a channel receiving the value each time it changes after the current version,
+// the channel is closed when ctx is done.
+//
+// Slow subscribers do not block writers, intermediate versions are skipped
+// and the subscriber always receives the latest value.
+func (w *Watched[T]) Subscribe(ctx context.Context) <-chan Versioned[T] {
+	ch := make(chan Versioned[T])
+	since := w.Version()
+	go func() {
+		defer close(ch)
+		for {
+			value, version, err := w.WaitChange(ctx, since)
+			if err != nil {
+				return
+			}
+			select {
+			case ch <- Versioned[T]{Value: value, Version: version}:
+				since = version
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,107 @@
+package gsync_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dashjay/gog/gsync"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatched(t *testing.T) {
+	t.Parallel()
+
+	t.Run("store update and compare and set", func(t *testing.T) {
+		w := gsync.NewWatched(map[string]int{})
+		_, version := w.Load()
+		assert.Equal(t, uint64(0), version)
+
+		assert.Equal(t, uint64(1), w.Store(map[string]int{"a": 1}))
+		assert.Equal(t, uint64(2), w.Update(func(m *map[string]int) {
+			(*m)["b"] = 2
+		}))
+		v, version := w.Load()
+		assert.Equal(t, map[string]int{"a": 1, "b": 2}, v)
+		assert.Equal(t, uint64(2), version)
+
+		version, swapped := w.CompareAndSet(1, map[string]int{})
+		assert.False(t, swapped)
+		assert.Equal(t, uint64(2), version)
+
+		version, swapped = w.CompareAndSet(2, map[string]int{"c": 3})
+		assert.True(t, swapped)
+		assert.Equal(t, uint64(3), version)
+		assert.Equal(t, uint64(3), w.Version())
+	})
+
+	t.Run("zero value", func(t *testing.T) {
+		var w gsync.Watched[int]
+		done := make(chan int)
+		go func() {
+			v, _, err := w.WaitChange(context.Background(), 0)
+			assert.Nil(t, err)
+			done <- v
+		}()
+		assert.Equal(t, uint64(1), w.Store(1))
+		assert.Equal(t, 1, <-done)
+		assert.Equal(t, uint64(2), w.Update(func(v *int) { *v++ }))
+	})
+
+	t.Run("wait change", func(t *testing.T) {
+		w := gsync.NewWatched("v0")
+
+		// already changed
+		w.Store("v1")
+		v, version, err := w.WaitChange(context.Background(), 0)
+		assert.Nil(t, err)
+		assert.Equal(t, "v1", v)
+		assert.Equal(t, uint64(1), version)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				v, version, err := w.WaitChange(context.Background(), 1)
+				assert.Nil(t, err)
+				assert.Equal(t, "v2", v)
+				assert.Equal(t, uint64(2), version)
+			}()
+		}
+		time.Sleep(time.Millisecond)
+		w.Store("v2")
+		wg.Wait()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		v, version, err = w.WaitChange(ctx, 2)
+		assert.Equal(t, context.Canceled, err)
+		assert.Equal(t, "", v)
+		assert.Equal(t, uint64(2), version)
+	})
+
+	t.Run("subscribe", func(t *testing.T) {
+		w := gsync.NewWatched(0)
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := w.Subscribe(ctx)
+
+		w.Store(1)
+		change := <-ch
+		assert.Equal(t, gsync.Versioned[int]{Value: 1, Version: 1}, change)
+
+		// intermediate versions are skipped for slow subscribers
+		w.Store(2)
+		w.Store(3)
+		change = <-ch
+		if change.Version == 2 {
+			change = <-ch
+		}
+		assert.Equal(t, gsync.Versioned[int]{Value: 3, Version: 3}, change)
+
+		cancel()
+		for range ch {
+		}
+	})
+}
@@ -0,0 +1,282 @@
+package gsync
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrQueueFull is returned by WorkerPool.Submit with SubmitReject policy when the queue is full.
+	ErrQueueFull = errors.New("gsync: worker pool queue is full")
+	// ErrWorkerPoolClosed is returned by WorkerPool.Submit after Shutdown.
+	ErrWorkerPoolClosed = errors.New("gsync: worker pool is shut down")
+)
+
+// SubmitPolicy decides what WorkerPool.Submit does when the queue is full.
+type SubmitPolicy int
+
+const (
+	// SubmitBlock blocks the submitter until there is room in the queue.
+	SubmitBlock SubmitPolicy = iota
+	// SubmitReject returns ErrQueueFull immediately.
+	SubmitReject
+)
+
+// WorkerPoolHooks are optional callbacks for metrics, they are called in the goroutine of the submitter or the worker.
+type WorkerPoolHooks struct {
+	// OnReject is called when a task is rejected because the queue is full.
+	OnReject func()
+	// OnStart is called when a worker starts a task, wait is the time spent in the queue.
+	OnStart func(wait time.Duration)
+	// OnDone is called when a task finishes, err is a *PanicError if the task panicked.
+	OnDone func(elapsed time.Duration, err error)
+}
+
+// WorkerPoolConfig configures a WorkerPool.
+type WorkerPoolConfig struct {
+	// MinWorkers is the number of workers always running, at least 1.
+	MinWorkers int
+	// MaxWorkers is the maximum number of workers, extra workers are started when all workers are busy,
+	// the pool has a fixed number of workers if MaxWorkers <= MinWorkers.
+	MaxWorkers int
+	// IdleTimeout is how long an extra worker waits for a task before it exits, IdleTimeout <= 0 means never.
+	IdleTimeout time.Duration
+	// QueueSize is the capacity of the submit queue.
+	QueueSize int
+	// Policy is applied when the queue is full.
+	Policy SubmitPolicy
+	Hooks  WorkerPoolHooks
+	// Clock is used for idle timeout and metrics, RealClock is used if nil.
+	Clock Clock
+}
+
+type workerTask[T, R any] struct {
+	in       T
+	future   *Future[R]
+	queuedAt time.Time
+}
+
+// WorkerPool runs a handler on the submitted inputs with a bounded number of goroutines and a bounded queue.
+type WorkerPool[T, R any] struct {
+	cfg     WorkerPoolConfig
+	handler func(ctx context.Context, in T) (R, error)
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	queue  chan workerTask[T, R]
+
+	workers int32
+	idle    int32
+	// pending is the number of submitted tasks not taken by a worker yet.
+	pending int32
+	wg      sync.WaitGroup
+
+	// mu protects queue from being closed while submitters are sending to it.
+	mu           sync.RWMutex
+	closed       bool
+	closing      chan struct{}
+	shutdownOnce sync.Once
+}
+
+// NewWorkerPool creates a WorkerPool running handler and starts cfg.MinWorkers workers.
+// The ctx passed to handler is canceled if Shutdown gives up waiting.
+func NewWorkerPool[T, R any](cfg WorkerPoolConfig, handler func(ctx context.Context, in T) (R, error)) *WorkerPool[T, R] {
+	if cfg.MinWorkers <= 0 {
+		cfg.MinWorkers = 1
+	}
+	if cfg.MaxWorkers < cfg.MinWorkers {
+		cfg.MaxWorkers = cfg.MinWorkers
+	}
+	if cfg.QueueSize < 0 {
+		cfg.QueueSize = 0
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = RealClock
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &WorkerPool[T, R]{
+		cfg:     cfg,
+		handler: handler,
+		ctx:     ctx,
+		cancel:  cancel,
+		queue:   make(chan workerTask[T, R], cfg.QueueSize),
+		closing: make(chan struct{}),
+	}
+	for i := 0; i < cfg.MinWorkers; i++ {
+		p.workers++
+		p.wg.Add(1)
+		go p.worker(false)
+	}
+	return p
+}
+
+// Submit puts in into the queue and returns a Future of the result.
+// With SubmitBlock policy, Submit blocks until there is room in the queue, ctx is done or the pool is shut down.
+func (p *WorkerPool[T, R]) Submit(ctx context.Context, in T) (*Future[R], error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return nil, ErrWorkerPoolClosed
+	}
+	task := workerTask[T, R]{in: in, future: newFuture[R](), queuedAt: p.cfg.Clock.Now()}
+	// each pending task needs an idle worker, otherwise an extra worker is started if MaxWorkers is not reached
+	started := false
+	if atomic.AddInt32(&p.pending, 1) > atomic.LoadInt32(&p.idle) {
+		started = p.addWorker()
+	}
+	select {
+	case p.queue <- task:
+		return task.future, nil
+	default:
+	}
+	if !started && p.cfg.Policy == SubmitReject {
+		atomic.AddInt32(&p.pending, -1)
+		if p.cfg.Hooks.OnReject != nil {
+			p.cfg.Hooks.OnReject()
+		}
+		return nil, ErrQueueFull
+	}
+	select {
+	case p.queue <- task:
+		return task.future, nil
+	case <-ctx.Done():
+		atomic.AddInt32(&p.pending, -1)
+		return nil, ctx.Err()
+	case <-p.closing:
+		atomic.AddInt32(&p.pending, -1)
+		return nil, ErrWorkerPoolClosed
+	}
+}
+
+// addWorker starts an extra worker if MaxWorkers is not reached, it returns true if a worker is started.
+func (p *WorkerPool[T, R]) addWorker() bool {
+	for {
+		n := atomic.LoadInt32(&p.workers)
+		if int(n) >= p.cfg.MaxWorkers {
+			return false
+		}
+		if atomic.CompareAndSwapInt32(&p.workers, n, n+1) {
+			p.wg.Add(1)
+			go p.worker(true)
+			return true
+		}
+	}
+}
+
+func (p *WorkerPool[T, R]) worker(extra bool) {
+	defer p.wg.Done()
+	for {
+		var idleTimeout <-chan time.Time
+		var timer Timer
+		if extra && p.cfg.IdleTimeout > 0 {
+			timer = p.cfg.Clock.NewTimer(p.cfg.IdleTimeout)
+			idleTimeout = timer.C()
+		}
+		atomic.AddInt32(&p.idle, 1)
+		select {
+		case task, ok := <-p.queue:
+			// idle is decremented first, so a submitter may start a needless worker but never misses one
+			atomic.AddInt32(&p.idle, -1)
+			if ok {
+				atomic.AddInt32(&p.pending, -1)
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+			if !ok {
+				atomic.AddInt32(&p.workers, -1)
+				return
+			}
+			p.run(task)
+		case <-idleTimeout:
+			atomic.AddInt32(&p.idle, -1)
+			atomic.AddInt32(&p.workers, -1)
+			return
+		}
+	}
+}
+
+func (p *WorkerPool[T, R]) run(task workerTask[T, R]) {
+	start := p.cfg.Clock.Now()
+	if p.cfg.Hooks.OnStart != nil {
+		p.cfg.Hooks.OnStart(start.Sub(task.queuedAt))
+	}
+	var val R
+	var err error
+	if err = p.ctx.Err(); err == nil {
+		val, err = p.call(task.in)
+	}
+	task.future.complete(val, err)
+	if p.cfg.Hooks.OnDone != nil {
+		p.cfg.Hooks.OnDone(p.cfg.Clock.Now().Sub(start), err)
+	}
+}
+
+func (p *WorkerPool[T, R]) call(in T) (val R, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = newPanicError(r)
+		}
+	}()
+	return p.handler(p.ctx, in)
+}
+
+// Workers returns the number of running workers.
+func (p *WorkerPool[T, R]) Workers() int {
+	return int(atomic.LoadInt32(&p.workers))
+}
+
+// QueueLen returns the number of tasks waiting in the queue.
+func (p *WorkerPool[T, R]) QueueLen() int {
+	return len(p.queue)
+}
+
+// Shutdown stops accepting new tasks and waits for the queued and running tasks to finish.
+// If ctx is done first, the ctx passed to handler is canceled, the queued tasks fail with context.Canceled,
+// and Shutdown returns ctx.Err().
+func (p *WorkerPool[T, R]) Shutdown(ctx context.Context) error {
+	p.shutdownOnce.Do(func() {
+		close(p.closing)
+		p.mu.Lock()
+		p.closed = true
+		close(p.queue)
+		p.mu.Unlock()
+	})
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		return ctx.Err()
+	}
+}
+
+// Map submits all inputs and waits for their results in order, it returns the first error.
+func (p *WorkerPool[T, R]) Map(ctx context.Context, inputs []T) ([]R, error) {
+	futures := make([]*Future[R], 0, len(inputs))
+	for _, in := range inputs {
+		f, err := p.Submit(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+		futures = append(futures, f)
+	}
+	out := make([]R, len(futures))
+	for i, f := range futures {
+		v, err := f.Await(ctx)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = v
+	}
+	return out, nil
+}
@@ -0,0 +1,224 @@
+package gsync_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dashjay/gog/gsync"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerPool(t *testing.T) {
+	t.Parallel()
+
+	t.Run("submit and map", func(t *testing.T) {
+		var started, done int32
+		p := gsync.NewWorkerPool(gsync.WorkerPoolConfig{
+			MinWorkers: 4,
+			QueueSize:  10,
+			Hooks: gsync.WorkerPoolHooks{
+				OnStart: func(time.Duration) {
+					atomic.AddInt32(&started, 1)
+				},
+				OnDone: func(time.Duration, error) {
+					atomic.AddInt32(&done, 1)
+				},
+			},
+		}, func(ctx context.Context, in int) (string, error) {
+			return strconv.Itoa(in), nil
+		})
+		assert.Equal(t, 4, p.Workers())
+
+		f, err := p.Submit(context.Background(), 1)
+		assert.Nil(t, err)
+		v, err := f.Get()
+		assert.Nil(t, err)
+		assert.Equal(t, "1", v)
+
+		out, err := p.Map(context.Background(), []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12"}, out)
+
+		assert.Nil(t, p.Shutdown(context.Background()))
+		assert.Equal(t, 0, p.Workers())
+		assert.Equal(t, int32(13), atomic.LoadInt32(&started))
+		assert.Equal(t, int32(13), atomic.LoadInt32(&done))
+
+		_, err = p.Submit(context.Background(), 1)
+		assert.Equal(t, gsync.ErrWorkerPoolClosed, err)
+		_, err = p.Map(context.Background(), []int{1})
+		assert.Equal(t, gsync.ErrWorkerPoolClosed, err)
+		assert.Nil(t, p.Shutdown(context.Background()))
+	})
+
+	t.Run("reject policy and blocking submit", func(t *testing.T) {
+		var rejected int32
+		block := make(chan struct{})
+		p := gsync.NewWorkerPool(gsync.WorkerPoolConfig{
+			QueueSize: 1,
+			Policy:    gsync.SubmitReject,
+			Hooks: gsync.WorkerPoolHooks{
+				OnReject: func() {
+					atomic.AddInt32(&rejected, 1)
+				},
+			},
+		}, func(ctx context.Context, in int) (int, error) {
+			<-block
+			return in, nil
+		})
+		f1, err := p.Submit(context.Background(), 1)
+		assert.Nil(t, err)
+		// wait until the worker takes the first task
+		for p.QueueLen() != 0 {
+			runtime.Gosched()
+		}
+		f2, err := p.Submit(context.Background(), 2)
+		assert.Nil(t, err)
+		_, err = p.Submit(context.Background(), 3)
+		assert.Equal(t, gsync.ErrQueueFull, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&rejected))
+		close(block)
+		v, _ := f1.Get()
+		assert.Equal(t, 1, v)
+		v, _ = f2.Get()
+		assert.Equal(t, 2, v)
+		assert.Nil(t, p.Shutdown(context.Background()))
+
+		block = make(chan struct{})
+		p = gsync.NewWorkerPool(gsync.WorkerPoolConfig{}, func(ctx context.Context, in int) (int, error) {
+			<-block
+			return in, nil
+		})
+		_, err = p.Submit(context.Background(), 1)
+		assert.Nil(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		_, err = p.Submit(ctx, 2)
+		assert.Equal(t, context.DeadlineExceeded, err)
+
+		// submitters blocked by a full queue are released by shutdown
+		submitErr := make(chan error)
+		go func() {
+			_, err := p.Submit(context.Background(), 3)
+			submitErr <- err
+		}()
+		shutdownErr := make(chan error)
+		go func() {
+			shutdownErr <- p.Shutdown(context.Background())
+		}()
+		assert.Equal(t, gsync.ErrWorkerPoolClosed, <-submitErr)
+		close(block)
+		assert.Nil(t, <-shutdownErr)
+	})
+
+	t.Run("elastic workers", func(t *testing.T) {
+		clock := gsync.NewManualClock(time.Now())
+		block := make(chan struct{})
+		p := gsync.NewWorkerPool(gsync.WorkerPoolConfig{
+			MinWorkers:  1,
+			MaxWorkers:  3,
+			IdleTimeout: time.Second,
+			QueueSize:   10,
+			Clock:       clock,
+		}, func(ctx context.Context, in int) (int, error) {
+			<-block
+			return in, nil
+		})
+		var futures []*gsync.Future[int]
+		for i := 0; i < 5; i++ {
+			f, err := p.Submit(context.Background(), i)
+			assert.Nil(t, err)
+			futures = append(futures, f)
+		}
+		assert.LessOrEqual(t, p.Workers(), 3)
+		assert.Greater(t, p.Workers(), 1)
+		close(block)
+		for i, f := range futures {
+			v, _ := f.Get()
+			assert.Equal(t, i, v)
+		}
+		// extra workers exit after idle timeout
+		for clock.Waiters() != p.Workers()-1 {
+			runtime.Gosched()
+		}
+		clock.Advance(time.Second)
+		for p.Workers() != 1 {
+			runtime.Gosched()
+		}
+		assert.Nil(t, p.Shutdown(context.Background()))
+	})
+
+	t.Run("elastic workers without queue", func(t *testing.T) {
+		block := make(chan struct{})
+		p := gsync.NewWorkerPool(gsync.WorkerPoolConfig{
+			MinWorkers: 1,
+			MaxWorkers: 4,
+		}, func(ctx context.Context, in int) (int, error) {
+			<-block
+			return in, nil
+		})
+		var futures []*gsync.Future[int]
+		for i := 0; i < 4; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			f, err := p.Submit(ctx, i)
+			cancel()
+			assert.Nil(t, err)
+			futures = append(futures, f)
+		}
+		assert.Equal(t, 4, p.Workers())
+
+		// all workers are busy and MaxWorkers is reached
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		_, err := p.Submit(ctx, 4)
+		assert.Equal(t, context.DeadlineExceeded, err)
+
+		close(block)
+		for i, f := range futures {
+			v, _ := f.Get()
+			assert.Equal(t, i, v)
+		}
+		assert.Nil(t, p.Shutdown(context.Background()))
+	})
+
+	t.Run("panic recovery and forced shutdown", func(t *testing.T) {
+		lastErr := gsync.NewLockedValue[error](nil)
+		p := gsync.NewWorkerPool(gsync.WorkerPoolConfig{
+			QueueSize: 10,
+			Hooks: gsync.WorkerPoolHooks{
+				OnDone: func(_ time.Duration, err error) {
+					if err != nil {
+						lastErr.SetValue(err)
+					}
+				},
+			},
+		}, func(ctx context.Context, in int) (int, error) {
+			if in == 0 {
+				panic("boom")
+			}
+			<-ctx.Done()
+			return in, ctx.Err()
+		})
+		f, _ := p.Submit(context.Background(), 0)
+		_, err := f.Get()
+		var pe *gsync.PanicError
+		assert.True(t, errors.As(err, &pe))
+		assert.Equal(t, "boom", pe.Value)
+		assert.Equal(t, err, lastErr.Load())
+
+		f1, _ := p.Submit(context.Background(), 1)
+		f2, _ := p.Submit(context.Background(), 2)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		assert.Equal(t, context.DeadlineExceeded, p.Shutdown(ctx))
+		_, err = f1.Get()
+		assert.Equal(t, context.Canceled, err)
+		_, err = f2.Get()
+		assert.Equal(t, context.Canceled, err)
+	})
+}
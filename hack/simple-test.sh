@@ -6,4 +6,5 @@ rootDir="$SCRIPT_DIR"/..
 cd "$rootDir"
 
 GOTOOLCHAIN=go1.18.10 go test ./... -v
-GOTOOLCHAIN=go1.23.2 go test ./... -v
\ No newline at end of file
+GOTOOLCHAIN=go1.23.2 go test ./... -v
+GOTOOLCHAIN=go1.23.2 go test -tags gogdebug ./gsync/ -v